@@ -0,0 +1,94 @@
+package main
+
+import (
+	"backend/config"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const usage = `Usage: configctl [-format yaml|json] [-env]
+
+Prints the effective configuration merged from the defaults, the embedded config files,
+the environment, .env and the secret sources, with the source of every value. Secrets
+are redacted. With -env, prints the environment variables overriding the config instead.
+`
+
+func main() {
+	flags := flag.NewFlagSet("configctl", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flags.PrintDefaults()
+	}
+	format := flags.String("format", "yaml", "output format: yaml or json")
+	env := flags.Bool("env", false, "print the supported environment variables as Markdown")
+	flags.Parse(os.Args[1:])
+
+	if *env {
+		fmt.Print(config.EnvDoc())
+		return
+	}
+
+	settings, err := config.Inspect()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load the configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch *format {
+	case "yaml":
+		err = printYAML(settings)
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(settings)
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to print the configuration: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// printYAML prints the settings as a YAML document with the source of each value as a
+// line comment.
+func printYAML(settings []config.Setting) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	for _, setting := range settings {
+		value := &yaml.Node{}
+		if err := value.Encode(setting.Value); err != nil {
+			return err
+		}
+		value.LineComment = setting.Source
+		parent := root
+		path := strings.Split(setting.Key, ".")
+		for _, key := range path[:len(path)-1] {
+			parent = child(parent, key)
+		}
+		parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: path[len(path)-1]}, value)
+	}
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// child returns the mapping under key in parent, adding it when missing.
+func child(parent *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(parent.Content); i += 2 {
+		if parent.Content[i].Value == key {
+			return parent.Content[i+1]
+		}
+	}
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, node)
+	return node
+}
@@ -4,10 +4,11 @@ import (
 	"backend/config"
 	"backend/internal/contexthelper"
 	"backend/internal/helper"
+	"backend/internal/metrics"
 	"backend/internal/queue"
+	"backend/internal/tracing"
 	"backend/pkg/logger"
 	"context"
-	"log"
 	"os/signal"
 	"syscall"
 	"time"
@@ -18,8 +19,19 @@ func main() {
 	if err != nil {
 		logger.Fatal("Nie można załadować konfiguracji: %v", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		logger.Fatal("%v", err)
+	}
+
+	logger.Init(cfg.LogLevel, cfg.LogFormat, contexthelper.LogAttrs)
 
-	logger.Init(cfg.LogLevel, contexthelper.GetRequestID)
+	// Tracing (OTLP lub stdout); propagacja kontekstu działa także przy wyłączonym
+	shutdownTracing, err := tracing.Init(context.Background(), cfg, "consumer")
+	if err != nil {
+		logger.Fatal("Nie udało się uruchomić tracingu: %v", err)
+	}
+	defer shutdownTracing()
+	queue.Configure(cfg.Queues)
 
 	// Kontekst z timeoutem na połączenie z DB
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
@@ -33,6 +45,13 @@ func main() {
 
 	logger.Info("Wszystkie usługi gotowe, start backendu...")
 
+	// Metryki Prometheusa na osobnym, wewnętrznym porcie
+	if cfg.Metrics.Enabled {
+		metrics.RegisterDB(db, "main")
+		metricsSrv := metrics.Start(cfg.Metrics.Host, cfg.Metrics.ConsumerPort)
+		defer metricsSrv.Close()
+	}
+
 	// Główny kontekst aplikacji – będzie anulowany na SIGINT/SIGTERM
 	appCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
@@ -42,26 +61,47 @@ func main() {
 	appCtx = contexthelper.SetRequestID(appCtx, "consumer")
 
 	logger.Info("🚀 Starting consumers...")
+	for _, q := range queue.TaskQueues() {
+		logger.Info("%s queue: %d worker(s), prefetch %d, %d retries from %s", q.Name, q.Workers, q.Prefetch, q.Retry.MaxRetries, q.Retry.Delay)
+	}
 	c := queue.NewConsumer()
 
 	// Warto dodać, żeby consumer dostał kontekst (zatrzyma się na cancel)
-	go func() {
-		if err := c.StartEmailConsumer(appCtx); err != nil {
-			log.Printf("Email consumer stopped with error: %v", err)
-		}
-	}()
-	go func() {
-		if err := c.StartReportConsumer(appCtx); err != nil {
-			log.Printf("Report consumer stopped with error: %v", err)
+	for _, q := range queue.TaskQueues() {
+		go func() {
+			if err := c.Consume(appCtx, q); err != nil {
+				logger.Error("%s consumer stopped with error: %v", q.Name, err)
+			}
+		}()
+	}
+
+	// Zadania cykliczne i odroczone – publikuje je tylko jedna replika (lider)
+	schedulerDone := make(chan struct{})
+	if cfg.Scheduler.Enabled {
+		scheduler := queue.NewScheduler(cfg.Scheduler)
+		if err := queue.ScheduleMaintenance(scheduler, cfg); err != nil {
+			logger.Fatal("Nieprawidłowa konfiguracja harmonogramu: %v", err)
 		}
-	}()
+		go func() {
+			defer close(schedulerDone)
+			if err := scheduler.Run(contexthelper.SetRequestID(appCtx, "scheduler")); err != nil {
+				logger.Error("Scheduler stopped with error: %v", err)
+			}
+		}()
+	} else {
+		close(schedulerDone)
+	}
 
 	// Czekaj na sygnał zakończenia
 	<-appCtx.Done()
 	logger.Info("⏹ Zatrzymywanie consumerów...")
 
-	// jeśli masz c.Stop() → można tu wywołać
-	// c.Stop()
+	// Kończy rozpoczęte zadania przed zamknięciem połączeń z DB i RabbitMQ (defer)
+	timeout := time.Duration(cfg.Queues.ShutdownTimeoutSeconds) * time.Second
+	if err := c.Stop(timeout); err != nil {
+		logger.Error("Nie wszystkie zadania zakończyły się w ciągu %v, zostaną ponowione: %v", timeout, err)
+	}
+	<-schedulerDone
 
 	logger.Info("✅ Consumers zatrzymani")
 }
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"backend/config"
+	"backend/internal/contexthelper"
+	"backend/internal/queue"
+	"backend/pkg/logger"
+	"backend/pkg/rabbitmq"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `Usage: queuectl <command> [flags]
+
+Commands:
+  queues                          list task queues
+  list   -queue NAME [-limit N]   show messages in the dead letter queue
+  replay -queue NAME [-ids a,b]   move dead letters back to the main queue (all without -ids)
+  purge  -queue NAME              remove all messages from the dead letter queue
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	command := os.Args[1]
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	queueName := flags.String("queue", "", "task queue name (e.g. email, report)")
+	limit := flags.Int("limit", 50, "maximum number of messages to list")
+	ids := flags.String("ids", "", "comma separated message IDs to replay")
+	flags.Parse(os.Args[2:])
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		logger.Fatal("Nie można załadować konfiguracji: %v", err)
+	}
+	logger.Init(cfg.LogLevel, cfg.LogFormat, contexthelper.LogAttrs)
+	queue.Configure(cfg.Queues)
+
+	if command == "queues" {
+		printQueues()
+		return
+	}
+
+	q, ok := queue.GetTaskQueue(*queueName)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown queue %q\n\n%s", *queueName, usage)
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	rabbitConn, err := rabbitmq.Dial(ctx, cfg.RabbitMQ.URL)
+	if err != nil {
+		logger.Fatal("Nie udało się połączyć z RabbitMQ: %v", err)
+	}
+	defer rabbitConn.Close()
+	ctx = contexthelper.SetRequestID(ctx, "queuectl")
+
+	switch command {
+	case "list":
+		list, err := queue.ListDeadLetters(ctx, rabbitConn, q, *limit)
+		if err != nil {
+			logger.Fatal("List failed: %v", err)
+		}
+		printDeadLetters(list)
+	case "replay":
+		var selected []string
+		if *ids != "" {
+			selected = strings.Split(*ids, ",")
+		}
+		replayed, err := queue.ReplayDeadLetters(ctx, rabbitConn, q, selected)
+		if err != nil {
+			logger.Fatal("Replay failed after %d messages: %v", replayed, err)
+		}
+		fmt.Printf("Replayed %d message(s) to %s\n", replayed, q.Queues.Main)
+	case "purge":
+		purged, err := queue.PurgeDeadLetters(ctx, rabbitConn, q)
+		if err != nil {
+			logger.Fatal("Purge failed: %v", err)
+		}
+		fmt.Printf("Purged %d message(s) from %s\n", purged, q.Queues.DLQ)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
+}
+
+func printQueues() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tMAIN\tDLQ\tWORKERS\tPREFETCH\tMAX RETRIES\tRETRY DELAYS")
+	for _, q := range queue.TaskQueues() {
+		delays := make([]string, 0, q.Retry.MaxRetries)
+		for attempt := 1; attempt <= q.Retry.MaxRetries; attempt++ {
+			delays = append(delays, q.Retry.DelayFor(attempt).String())
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%s\n", q.Name, q.Queues.Main, q.Queues.DLQ, q.Workers, q.Prefetch, q.Retry.MaxRetries, strings.Join(delays, ","))
+	}
+	w.Flush()
+}
+
+func printDeadLetters(list []queue.DeadLetter) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTASK\tRETRIES\tFAILED AT\tLAST ERROR\tDATA")
+	for _, dl := range list {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n", dl.Id, dl.Task, dl.Retries, dl.FailedAt, dl.LastError, dl.Data)
+	}
+	w.Flush()
+	fmt.Printf("%d message(s)\n", len(list))
+}
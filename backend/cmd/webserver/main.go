@@ -11,8 +11,12 @@ import (
 
 	"backend/config"
 	"backend/internal/contexthelper"
+	"backend/internal/featureflag"
 	"backend/internal/handler"
 	"backend/internal/helper"
+	"backend/internal/metrics"
+	"backend/internal/queue"
+	"backend/internal/tracing"
 	"backend/internal/router"
 	"backend/pkg/logger"
 )
@@ -22,9 +26,20 @@ func main() {
 	if err != nil {
 		logger.Fatal("Nie można załadować konfiguracji: %v", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		logger.Fatal("%v", err)
+	}
+
+	logger.Init(cfg.LogLevel, cfg.LogFormat, contexthelper.LogAttrs)
 
-	logger.Init(cfg.LogLevel, contexthelper.GetRequestID)
+	// Tracing (OTLP lub stdout); propagacja kontekstu działa także przy wyłączonym
+	shutdownTracing, err := tracing.Init(context.Background(), cfg, "webserver")
+	if err != nil {
+		logger.Fatal("Nie udało się uruchomić tracingu: %v", err)
+	}
+	defer shutdownTracing()
 	logger.Info("Uruchamianie aplikacji: %s", cfg.AppName)
+	queue.Configure(cfg.Queues)
 
 	// Kontekst z timeoutem na połączenie z DB
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
@@ -39,12 +54,28 @@ func main() {
 
 	logger.Info("Wszystkie usługi gotowe, start backendu...")
 
+	// Metryki Prometheusa na osobnym, wewnętrznym porcie
+	if cfg.Metrics.Enabled {
+		metrics.RegisterDB(db, "main")
+		metricsSrv := metrics.Start(cfg.Metrics.Host, cfg.Metrics.WebServerPort)
+		defer metricsSrv.Close()
+	}
+
+	// Flagi funkcji są odświeżane z bazy w tle, do tego czasu obowiązują wartości z konfiguracji
+	flags := featureflag.NewStore(db, featureflag.Defaults(cfg))
+	if err := flags.Refresh(ctx); err != nil {
+		logger.Error("Nie udało się wczytać flag funkcji: %v", err)
+	}
+	flagsCtx, stopFlags := context.WithCancel(context.Background())
+	defer stopFlags()
+	go flags.Run(flagsCtx, time.Duration(cfg.FeatureFlags.RefreshIntervalSeconds)*time.Second)
+
 	// Inicjalizacja handlerów i routera
 	h := handler.NewHandler()
 	webHostPort := fmt.Sprintf("%s:%v", cfg.WebServer.Host, cfg.WebServer.HTTPPort)
 	srv := &http.Server{
 		Addr:    webHostPort,
-		Handler: router.SetupRouter(h, cfg, db, rabbitConn),
+		Handler: router.SetupRouter(h, cfg, db, rabbitConn, flags),
 	}
 	
 	// Obsługa sygnałów (graceful shutdown)
@@ -61,11 +92,15 @@ func main() {
 	<-stop // czekaj na SIGINT/SIGTERM
 	logger.Info("Zatrzymywanie aplikacji...")
 
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	// Najpierw przestajemy przyjmować żądania i czekamy na rozpoczęte,
+	// dopiero potem zamykane są połączenia z DB i RabbitMQ (defer)
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), time.Duration(cfg.WebServer.ShutdownTimeoutSeconds)*time.Second)
 	defer shutdownCancel()
 
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		logger.Error("Błąd przy zamykaniu serwera: %v", err)
+		// Przerywa pozostałe żądania, zanim zamkniemy połączenia, z których korzystają
+		srv.Close()
 	}
 
 	logger.Info("Aplikacja zatrzymana.")
@@ -0,0 +1,206 @@
+package config
+
+//go:generate sh -c "go run ../cmd/configctl -env > ENV.md"
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The environment variables overriding the config are derived from the Config struct.
+// A field is overridden by the variable in its env tag or, without one, by the prefix of
+// its parent followed by its mapstructure name in upper case, e.g. QUEUE_EMAIL_WORKERS.
+// The env tag of a struct field is the prefix of its fields. Maps are set one key at a
+// time: SCHEDULER_JOBS_PRUNE_QUEUE_HISTORY sets scheduler.jobs.prune_queue_history.
+// Empty variables are ignored.
+
+// EnvVar is an environment variable overriding the setting Key (e.g. "database.port").
+type EnvVar struct {
+	Name string
+	Key  string
+	Type string
+
+	field []int
+	kind  reflect.Type
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+var envVars = collectEnvVars(reflect.TypeOf(Config{}), nil, "", "")
+
+// EnvVars returns every environment variable overriding the config, in the order of the
+// Config struct.
+func EnvVars() []EnvVar {
+	return append([]EnvVar(nil), envVars...)
+}
+
+func collectEnvVars(t reflect.Type, index []int, prefix, keyPrefix string) []EnvVar {
+	var vars []EnvVar
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("mapstructure")
+		if !field.IsExported() || key == "" {
+			continue
+		}
+		name, ok := field.Tag.Lookup("env")
+		if !ok {
+			name = strings.ToUpper(key)
+			if prefix != "" {
+				name = prefix + "_" + name
+			}
+		}
+		if keyPrefix != "" {
+			key = keyPrefix + "." + key
+		}
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		if field.Type.Kind() == reflect.Struct {
+			vars = append(vars, collectEnvVars(field.Type, fieldIndex, name, key)...)
+			continue
+		}
+		vars = append(vars, EnvVar{
+			Name:  name,
+			Key:   key,
+			Type:  envTypeName(field.Type),
+			field: fieldIndex,
+			kind:  field.Type,
+		})
+	}
+	return vars
+}
+
+func envTypeName(t reflect.Type) string {
+	switch {
+	case t == durationType:
+		return "duration (e.g. 90s, 5m)"
+	case t.Kind() == reflect.String:
+		return "string"
+	case t.Kind() == reflect.Bool:
+		return "boolean (true, false, 1, 0)"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		return "integer"
+	case t.Kind() == reflect.Uint8:
+		return "integer (0-255)"
+	case t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uint64:
+		return "non-negative integer"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return "number"
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String:
+		return "list (comma separated)"
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String:
+		return "string, one variable per key"
+	}
+	panic(fmt.Sprintf("config: no environment binding for %s", t))
+}
+
+// bindEnv sets every setting whose environment variable is set. Values that cannot be
+// parsed are reported by Validate and keep the setting unchanged.
+func (c *Config) bindEnv() {
+	root := reflect.ValueOf(c).Elem()
+	for _, envVar := range envVars {
+		target := root.FieldByIndex(envVar.field)
+		if envVar.kind.Kind() == reflect.Map {
+			c.bindEnvMap(envVar.Name+"_", target)
+			continue
+		}
+		value := os.Getenv(envVar.Name)
+		if value == "" {
+			continue
+		}
+		if err := setEnvValue(target, value); err != nil {
+			c.invalidEnv(envVar.Name, err)
+		}
+	}
+}
+
+// bindEnvMap sets the keys of a map from the variables starting with prefix; the rest of
+// the name in lower case is the key.
+func (c *Config) bindEnvMap(prefix string, target reflect.Value) {
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		if !strings.HasPrefix(name, prefix) || len(name) == len(prefix) || value == "" {
+			continue
+		}
+		if target.IsNil() {
+			target.Set(reflect.MakeMap(target.Type()))
+		}
+		key := strings.ToLower(strings.TrimPrefix(name, prefix))
+		target.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(value))
+	}
+}
+
+func setEnvValue(target reflect.Value, value string) error {
+	if target.Type() == durationType {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		target.SetInt(int64(duration))
+		return nil
+	}
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(value)
+	case reflect.Bool:
+		boolValue, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		target.SetBool(boolValue)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		intValue, err := strconv.ParseInt(value, 10, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetInt(intValue)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		uintValue, err := strconv.ParseUint(value, 10, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetUint(uintValue)
+	case reflect.Float32, reflect.Float64:
+		floatValue, err := strconv.ParseFloat(value, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetFloat(floatValue)
+	case reflect.Slice:
+		target.Set(reflect.ValueOf(splitList(value)))
+	}
+	return nil
+}
+
+// splitList splits a comma separated environment value, skipping empty items.
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// EnvDoc returns the list of environment variables as a Markdown table, as in ENV.md.
+func EnvDoc() string {
+	var b strings.Builder
+	b.WriteString("# Environment variables\n\n")
+	b.WriteString("<!-- Generated by `go generate ./config`, do not edit. -->\n\n")
+	b.WriteString("Every setting of the config files can be overridden by an environment variable; empty\n")
+	b.WriteString("variables are ignored. Values that cannot be parsed stop the binaries on startup.\n\n")
+	b.WriteString("| Variable | Setting | Type |\n")
+	b.WriteString("|---|---|---|\n")
+	for _, envVar := range envVars {
+		name, key := envVar.Name, envVar.Key
+		if envVar.kind.Kind() == reflect.Map {
+			name, key = name+"_<KEY>", key+".<key>"
+		}
+		fmt.Fprintf(&b, "| `%s` | `%s` | %s |\n", name, key, envVar.Type)
+	}
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBindEnv_ParsesValues(t *testing.T) {
+	t.Setenv("DB_PORT", "3307")
+	t.Setenv("SCHEDULER_ENABLED", "0")
+	t.Setenv("QUEUE_REPORT_RETRY_BACKOFF_FACTOR", "1.5")
+	t.Setenv("ACCESS_TOKEN_TTL_MINUTES", "15")
+	t.Setenv("ADMIN_EMAILS", "a@example.com, ,b@example.com")
+	t.Setenv("SCHEDULER_JOBS_PRUNE_QUEUE_HISTORY", "0 3 * * *")
+	t.Setenv("DEFAULT_LANGUAGE", "en")
+	t.Setenv("SMTP_HOST", "")
+
+	cfg := Config{Scheduler: SchedulerConfig{Enabled: true}, Email: EmailConfig{SMTPHost: "smtp.example.com"}}
+	cfg.bindEnv()
+
+	if len(cfg.envProblems) > 0 {
+		t.Fatalf("unexpected problems: %v", cfg.envProblems)
+	}
+	if cfg.DB.Port != 3307 || cfg.Scheduler.Enabled || cfg.Queues.Report.RetryBackoffFactor != 1.5 || cfg.Token.AccessTokenTtlMinutes != 15 {
+		t.Errorf("unexpected values: %+v", cfg)
+	}
+	if strings.Join(cfg.Admin.Emails, ";") != "a@example.com;b@example.com" {
+		t.Errorf("unexpected admin emails: %q", cfg.Admin.Emails)
+	}
+	if cfg.Scheduler.Jobs["prune_queue_history"] != "0 3 * * *" {
+		t.Errorf("unexpected scheduler jobs: %v", cfg.Scheduler.Jobs)
+	}
+	if cfg.DefaultLanguage != "en" {
+		t.Errorf("expected default language en, got %q", cfg.DefaultLanguage)
+	}
+	if cfg.Email.SMTPHost != "smtp.example.com" {
+		t.Errorf("expected an empty variable to be ignored, got %q", cfg.Email.SMTPHost)
+	}
+}
+
+func TestBindEnv_ReportsInvalidValues(t *testing.T) {
+	t.Setenv("DB_PORT", "mysql")
+	t.Setenv("REGISTER_ENABLED", "yes")
+	t.Setenv("REFRESH_TOKEN_TTL_DAYS", "365")
+
+	cfg := Config{DB: DBConfig{Port: 3306}, Register: RegisterConfig{Enabled: true}}
+	cfg.bindEnv()
+
+	problems := strings.Join(cfg.envProblems, "\n")
+	for _, name := range []string{"DB_PORT", "REGISTER_ENABLED", "REFRESH_TOKEN_TTL_DAYS"} {
+		if !strings.Contains(problems, name+":") {
+			t.Errorf("expected a problem for %s, got %v", name, cfg.envProblems)
+		}
+	}
+	if cfg.DB.Port != 3306 || !cfg.Register.Enabled {
+		t.Errorf("expected invalid values to keep the settings, got %d, %v", cfg.DB.Port, cfg.Register.Enabled)
+	}
+}
+
+func TestSetEnvValue_Duration(t *testing.T) {
+	var timeout time.Duration
+	if err := setEnvValue(reflect.ValueOf(&timeout).Elem(), "90s"); err != nil || timeout != 90*time.Second {
+		t.Errorf("expected 90s, got %v, %v", timeout, err)
+	}
+	if err := setEnvValue(reflect.ValueOf(&timeout).Elem(), "90"); err == nil {
+		t.Error("expected an error for a duration without unit")
+	}
+}
+
+func TestEnvDoc_UpToDate(t *testing.T) {
+	doc, err := os.ReadFile("ENV.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(doc) != EnvDoc() {
+		t.Error("ENV.md is out of date, run go generate ./config")
+	}
+}
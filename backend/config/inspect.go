@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sources of the configuration values reported by Inspect, besides the names of the
+// embedded config files.
+const (
+	SourceDefault     = "default"
+	SourceEnvironment = "environment"
+	SourceDotEnv      = ".env"
+	SourceSecret      = "secret source"
+	SourceDerived     = "derived"
+)
+
+// Redacted replaces secrets in the output of Inspect.
+const Redacted = "xxxxx"
+
+var secretKeys = map[string]bool{
+	"token.jwt_secret":  true,
+	"database.password": true,
+	"rabbitmq.password": true,
+	"email.password":    true,
+}
+
+var dsnPasswordRegexp = regexp.MustCompile(`^([^:@/]*):[^@]*@`)
+
+// Setting is a value of the effective configuration, keyed by its path in the config files
+// (e.g. "database.port"), with the source that set it.
+type Setting struct {
+	Key    string `json:"key"`
+	Value  any    `json:"value"`
+	Source string `json:"source"`
+}
+
+// Inspect loads the configuration the way the binaries do and returns every setting in
+// the order of the config struct, with secrets redacted. A value set to the same value
+// again by a later source keeps the earlier source.
+func Inspect() ([]Setting, error) {
+	sources := map[string]string{}
+	previous := map[string]string{}
+	var observeErr error
+	cfg, err := load(func(source string, cfg *Config) {
+		settings, err := flatten(cfg)
+		if err != nil {
+			observeErr = err
+			return
+		}
+		for _, setting := range settings {
+			value := fmt.Sprint(setting.Value)
+			if old, ok := previous[setting.Key]; !ok || old != value {
+				sources[setting.Key] = source
+				previous[setting.Key] = value
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if observeErr != nil {
+		return nil, observeErr
+	}
+
+	settings, err := flatten(cfg)
+	if err != nil {
+		return nil, err
+	}
+	for i := range settings {
+		settings[i].Source = sources[settings[i].Key]
+		settings[i].Value = redact(settings[i].Key, settings[i].Value)
+	}
+	return settings, nil
+}
+
+// flatten returns the leaf values of cfg as encoded in YAML.
+func flatten(cfg *Config) ([]Setting, error) {
+	var doc yaml.Node
+	if err := doc.Encode(cfg); err != nil {
+		return nil, err
+	}
+	var settings []Setting
+	var walk func(prefix string, node *yaml.Node) error
+	walk = func(prefix string, node *yaml.Node) error {
+		if node.Kind == yaml.MappingNode && len(node.Content) > 0 {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := node.Content[i].Value
+				if prefix != "" {
+					key = prefix + "." + key
+				}
+				if err := walk(key, node.Content[i+1]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		settings = append(settings, Setting{Key: prefix, Value: value})
+		return nil
+	}
+	return settings, walk("", &doc)
+}
+
+func redact(key string, value any) any {
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return value
+	}
+	switch {
+	case secretKeys[key]:
+		return Redacted
+	case key == "database.dsn":
+		return dsnPasswordRegexp.ReplaceAllString(str, "${1}:"+Redacted+"@")
+	case key == "rabbitmq.url":
+		if u, err := url.Parse(str); err == nil {
+			return u.Redacted()
+		}
+		return Redacted
+	}
+	return value
+}
@@ -0,0 +1,43 @@
+package config_test
+
+import (
+	"backend/config"
+	"testing"
+)
+
+func TestInspect_SourcesAndRedaction(t *testing.T) {
+	t.Setenv("APP_ENV", "dev")
+	t.Setenv("JWT_SECRET", "env-jwt-secret")
+	t.Setenv("DB_PORT", "3307")
+	t.Setenv("LOG_LEVEL", "")
+	t.Setenv("APP_NAME", "")
+
+	settings, err := config.Inspect()
+	if err != nil {
+		t.Fatalf("Inspect failed: %v", err)
+	}
+	byKey := map[string]config.Setting{}
+	for _, setting := range settings {
+		byKey[setting.Key] = setting
+	}
+
+	expected := map[string]config.Setting{
+		"token.jwt_secret": {Value: config.Redacted, Source: config.SourceEnvironment},
+		"database.port":    {Value: 3307, Source: config.SourceEnvironment},
+		"log_level":        {Value: "debug", Source: "config_dev.yaml"},
+		"app_name":         {Value: "MyWebApp", Source: "config.yaml"},
+	}
+	for key, want := range expected {
+		got, ok := byKey[key]
+		if !ok {
+			t.Errorf("%s: missing", key)
+			continue
+		}
+		if got.Value != want.Value || got.Source != want.Source {
+			t.Errorf("%s: expected %v from %s, got %v from %s", key, want.Value, want.Source, got.Value, got.Source)
+		}
+	}
+	if dsn := byKey["database.dsn"].Value.(string); dsn == "" || byKey["database.dsn"].Source != config.SourceDerived {
+		t.Errorf("expected a derived DSN, got %q from %s", dsn, byKey["database.dsn"].Source)
+	}
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SecretSource provides the secrets of the configuration by their environment variable
+// name, e.g. "JWT_SECRET". Lookup returns ok == false when the source does not have the
+// secret, so the next source or the value from the environment and config files is used.
+type SecretSource interface {
+	Lookup(name string) (value string, ok bool, err error)
+}
+
+// secretFields are the secret settings, which are looked up in the secret sources after
+// the environment variables are applied.
+var secretFields = []struct {
+	name  string
+	field func(cfg *Config) *string
+}{
+	{"JWT_SECRET", func(cfg *Config) *string { return &cfg.Token.JwtSecret }},
+	{"DB_PASS", func(cfg *Config) *string { return &cfg.DB.Pass }},
+	{"RABBITMQ_PASS", func(cfg *Config) *string { return &cfg.RabbitMQ.Password }},
+	{"SMTP_PASSWORD", func(cfg *Config) *string { return &cfg.Email.Password }},
+}
+
+var secretSources []SecretSource
+
+// RegisterSecretSource adds a source asked for secrets after the <NAME>_FILE variables and
+// SECRETS_DIR. It must be called before the configuration is loaded.
+func RegisterSecretSource(source SecretSource) {
+	secretSources = append(secretSources, source)
+}
+
+// defaultSecretSources returns the built-in sources followed by the registered ones.
+func defaultSecretSources() []SecretSource {
+	sources := []SecretSource{FileEnvSecretSource{}}
+	if dir := os.Getenv("SECRETS_DIR"); dir != "" {
+		sources = append(sources, DirSecretSource{Dir: dir})
+	}
+	return append(sources, secretSources...)
+}
+
+// loadSecrets sets every secret found in sources; the first source having it wins. Failed
+// lookups are reported by Validate.
+func (c *Config) loadSecrets(sources []SecretSource) {
+	for _, secret := range secretFields {
+		for _, source := range sources {
+			value, ok, err := source.Lookup(secret.name)
+			if err != nil {
+				c.invalidEnv(secret.name, err)
+				break
+			}
+			if ok {
+				*secret.field(c) = value
+				break
+			}
+		}
+	}
+}
+
+// FileEnvSecretSource reads a secret from the file named by the <NAME>_FILE environment
+// variable, as used for Docker and Kubernetes secrets.
+type FileEnvSecretSource struct{}
+
+func (FileEnvSecretSource) Lookup(name string) (string, bool, error) {
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return "", false, nil
+	}
+	if os.Getenv(name) != "" {
+		return "", false, fmt.Errorf("both %s and %s_FILE are set", name, name)
+	}
+	return readSecretFile(path)
+}
+
+// DirSecretSource reads a secret from the file named after it in Dir, e.g.
+// /run/secrets/JWT_SECRET. Missing files are skipped.
+type DirSecretSource struct {
+	Dir string
+}
+
+func (s DirSecretSource) Lookup(name string) (string, bool, error) {
+	value, ok, err := readSecretFile(filepath.Join(s.Dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", false, nil
+	}
+	return value, ok, err
+}
+
+func readSecretFile(path string) (string, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("read secret file: %w", err)
+	}
+	// Editors and "echo" add a trailing newline, which is not part of the secret
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
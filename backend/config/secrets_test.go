@@ -0,0 +1,48 @@
+package config_test
+
+import (
+	"backend/config"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileEnvSecretSource_ReadsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwt_secret")
+	if err := os.WriteFile(path, []byte("file-secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("JWT_SECRET_FILE", path)
+
+	value, ok, err := config.FileEnvSecretSource{}.Lookup("JWT_SECRET")
+
+	if err != nil || !ok || value != "file-secret" {
+		t.Errorf("expected file-secret, got %q, %v, %v", value, ok, err)
+	}
+}
+
+func TestFileEnvSecretSource_ConflictingEnv(t *testing.T) {
+	t.Setenv("DB_PASS", "env-secret")
+	t.Setenv("DB_PASS_FILE", filepath.Join(t.TempDir(), "db_pass"))
+
+	if _, _, err := (config.FileEnvSecretSource{}).Lookup("DB_PASS"); err == nil {
+		t.Error("expected an error when both DB_PASS and DB_PASS_FILE are set")
+	}
+}
+
+func TestDirSecretSource(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "SMTP_PASSWORD"), []byte("smtp-secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	source := config.DirSecretSource{Dir: dir}
+
+	value, ok, err := source.Lookup("SMTP_PASSWORD")
+	if err != nil || !ok || value != "smtp-secret" {
+		t.Errorf("expected smtp-secret, got %q, %v, %v", value, ok, err)
+	}
+	if _, ok, err := source.Lookup("RABBITMQ_PASS"); ok || err != nil {
+		t.Errorf("expected a missing secret to be skipped, got %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"backend/locale"
+)
+
+// minProdJwtSecretLength is the minimum length of the JWT secret outside of development.
+const minProdJwtSecretLength = 32
+
+var (
+	logLevels  = []string{"debug", "info", "warn", "error"}
+	logFormats = []string{"text", "json"}
+	exporters  = []string{"otlp", "stdout"}
+)
+
+// ValidationError lists every problem found by Config.Validate.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate checks the loaded configuration, including the environment variables that could
+// not be parsed, and returns a *ValidationError listing all problems at once.
+func (c *Config) Validate() error {
+	v := &validator{problems: append([]string(nil), c.envProblems...)}
+
+	v.check(slices.Contains(logLevels, c.LogLevel), "log_level: must be one of %s, got %q", strings.Join(logLevels, ", "), c.LogLevel)
+	v.check(slices.Contains(logFormats, c.LogFormat), "log_format: must be one of %s, got %q", strings.Join(logFormats, ", "), c.LogFormat)
+	v.check(locale.HasLanguage(c.DefaultLanguage), "default_language: no translations for %q", c.DefaultLanguage)
+
+	v.port("web_server.http_port", c.WebServer.HTTPPort)
+	v.min("web_server.shutdown_timeout_seconds", c.WebServer.ShutdownTimeoutSeconds, 0)
+	v.url("frontend.base_url", c.Frontend.BaseURL)
+
+	if c.DB.DSN == "" {
+		v.required("database.host", c.DB.Host)
+		v.port("database.port", c.DB.Port)
+		v.required("database.user", c.DB.User)
+		v.required("database.dbname", c.DB.DbName)
+	}
+	if c.RabbitMQ.URL == "" {
+		v.required("rabbitmq.host", c.RabbitMQ.Host)
+		v.port("rabbitmq.port", c.RabbitMQ.Port)
+	}
+
+	if c.Email.SMTPHost != "" {
+		port, err := strconv.Atoi(c.Email.SMTPPort)
+		v.check(err == nil && port > 0 && port <= 65535, "email.smtp_port: must be a port number, got %q", c.Email.SMTPPort)
+		v.required("email.from", c.Email.From)
+	}
+
+	v.required("token.jwt_secret", c.Token.JwtSecret)
+	v.check(c.Token.AccessTokenTtlMinutes > 0, "token.access_token_ttl_minutes: must be at least 1")
+	v.check(c.Token.RefreshTokenTtlDays > 0, "token.refresh_token_ttl_days: must be at least 1")
+
+	v.min("register.expiration_days", c.Register.ExpirationDays, 1)
+	v.min("reset_password.expiration_days", c.ResetPassword.ExpirationDays, 1)
+	v.min("email_change.expiration_days", c.EmailChange.ExpirationDays, 1)
+	v.min("confirmation_tokens.retention_days", c.ConfirmationTokens.RetentionDays, 0)
+	v.min("confirmation_tokens.resend_cooldown_seconds", c.ConfirmationTokens.ResendCooldownSeconds, 0)
+	v.min("confirmation_tokens.resend_max", c.ConfirmationTokens.ResendMax, 0)
+	v.min("account_deletion.grace_period_days", c.AccountDeletion.GracePeriodDays, 0)
+	v.min("account_deletion.purge_interval_minutes", c.AccountDeletion.PurgeIntervalMinutes, 1)
+	v.min("data_export.link_ttl_hours", c.DataExport.LinkTtlHours, 1)
+	v.required("data_export.storage_dir", c.DataExport.StorageDir)
+	v.required("reports.storage_dir", c.Reports.StorageDir)
+	v.min("feature_flags.refresh_interval_seconds", c.FeatureFlags.RefreshIntervalSeconds, 1)
+	if c.Metrics.Enabled {
+		v.port("metrics.webserver_port", c.Metrics.WebServerPort)
+		v.port("metrics.consumer_port", c.Metrics.ConsumerPort)
+		v.check(c.Metrics.WebServerPort != c.WebServer.HTTPPort, "metrics.webserver_port: must differ from web_server.http_port")
+	}
+	if c.Tracing.Enabled {
+		v.check(slices.Contains(exporters, c.Tracing.Exporter), "tracing.exporter: must be one of %s, got %q", strings.Join(exporters, ", "), c.Tracing.Exporter)
+		v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio: must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+	}
+
+	v.min("queues.shutdown_timeout_seconds", c.Queues.ShutdownTimeoutSeconds, 0)
+	v.queue("queues.email", c.Queues.Email)
+	v.queue("queues.report", c.Queues.Report)
+	v.queue("queues.maintenance", c.Queues.Maintenance)
+	v.min("scheduler.poll_interval_seconds", c.Scheduler.PollIntervalSeconds, 0)
+
+	if !c.IsDevEnv() {
+		// Secrets must be configured for the environment, the embedded ones are for development only
+		v.check(len(c.Token.JwtSecret) >= minProdJwtSecretLength, "token.jwt_secret: must be at least %d characters outside of dev", minProdJwtSecretLength)
+		if c.DB.DSN == "" {
+			v.required("database.password", c.DB.Pass)
+		}
+		if c.Email.SMTPHost != "" && c.Email.Username != "" {
+			v.required("email.password", c.Email.Password)
+		}
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) check(ok bool, format string, args ...any) {
+	if !ok {
+		v.problems = append(v.problems, fmt.Sprintf(format, args...))
+	}
+}
+
+func (v *validator) required(name, value string) {
+	v.check(strings.TrimSpace(value) != "", "%s: is required", name)
+}
+
+func (v *validator) min(name string, value, min int) {
+	v.check(value >= min, "%s: must be at least %d, got %d", name, min, value)
+}
+
+func (v *validator) port(name string, port int) {
+	v.check(port > 0 && port <= 65535, "%s: must be a port number, got %d", name, port)
+}
+
+func (v *validator) url(name, value string) {
+	u, err := url.Parse(value)
+	v.check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "%s: must be an absolute http(s) URL, got %q", name, value)
+}
+
+func (v *validator) queue(name string, q QueueConfig) {
+	// Zero values keep the defaults of the queue
+	v.min(name+".workers", q.Workers, 0)
+	v.min(name+".prefetch", q.Prefetch, 0)
+	v.min(name+".max_retries", q.MaxRetries, 0)
+	v.min(name+".retry_delay_ms", q.RetryDelayMs, 0)
+	v.check(q.RetryBackoffFactor >= 0, "%s.retry_backoff_factor: must not be negative, got %v", name, q.RetryBackoffFactor)
+}
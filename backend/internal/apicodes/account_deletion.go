@@ -0,0 +1,13 @@
+package apicodes
+
+const (
+	API_Account_Deletion_Scheduled           = 1900
+	API_Account_Deletion_Invalid_Credentials = 1901
+	API_Account_Deletion_Already_Scheduled   = 1902
+)
+
+var accountDeletionCodeDescriptions = map[int]string{
+	API_Account_Deletion_Scheduled:           "Account deletion scheduled",
+	API_Account_Deletion_Invalid_Credentials: "Invalid credentials",
+	API_Account_Deletion_Already_Scheduled:   "Account deletion is already scheduled",
+}
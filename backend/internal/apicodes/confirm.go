@@ -4,10 +4,12 @@ const (
 	API_Confirm_Success       = 1300
 	API_Confirm_Failure       = 1301
 	API_Confirm_Invalid_Token = 1302
+	API_Confirm_Already_Used  = 1303
 )
 
 var confirmCodeDescriptions = map[int]string{
 	API_Confirm_Success:       "Confirmation successful",
 	API_Confirm_Failure:       "Confirmation failed",
 	API_Confirm_Invalid_Token: "Invalid confirmation token",
+	API_Confirm_Already_Used:  "Confirmation token was already used",
 }
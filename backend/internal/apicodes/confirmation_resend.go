@@ -0,0 +1,13 @@
+package apicodes
+
+const (
+	API_Confirmation_Resend_Success           = 2200
+	API_Confirmation_Resend_Not_Found         = 2201
+	API_Confirmation_Resend_Too_Many_Requests = 2202
+)
+
+var confirmationResendCodeDescriptions = map[int]string{
+	API_Confirmation_Resend_Success:           "Confirmation email resent",
+	API_Confirmation_Resend_Not_Found:         "No pending confirmation found",
+	API_Confirmation_Resend_Too_Many_Requests: "Confirmation email was resent too recently or too often",
+}
@@ -0,0 +1,9 @@
+package apicodes
+
+const (
+	API_Data_Export_Requested = 2000
+)
+
+var dataExportCodeDescriptions = map[int]string{
+	API_Data_Export_Requested: "Data export requested",
+}
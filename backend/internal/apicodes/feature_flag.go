@@ -0,0 +1,13 @@
+package apicodes
+
+const (
+	API_Feature_Flag_Updated   = 2300
+	API_Feature_Disabled       = 2301
+	API_Feature_Flag_Not_Found = 2302
+)
+
+var featureFlagCodeDescriptions = map[int]string{
+	API_Feature_Flag_Updated:   "Feature flag updated",
+	API_Feature_Disabled:       "This feature is currently disabled",
+	API_Feature_Flag_Not_Found: "Feature flag not found",
+}
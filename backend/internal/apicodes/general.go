@@ -36,6 +36,11 @@ func init() {
 		passwordResetCodeDescriptions,
 		passwordChangeCodeDescriptions,
 		logoutCodeDescriptions,
+		accountDeletionCodeDescriptions,
+		dataExportCodeDescriptions,
+		reportCodeDescriptions,
+		confirmationResendCodeDescriptions,
+		featureFlagCodeDescriptions,
 		// Add other code maps here
 
 		// general errors at the end to override any duplicates
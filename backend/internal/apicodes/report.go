@@ -0,0 +1,15 @@
+package apicodes
+
+const (
+	API_Report_Queued    = 2100
+	API_Report_Forbidden = 2101
+	API_Report_Not_Found = 2102
+	API_Report_Not_Ready = 2103
+)
+
+var reportCodeDescriptions = map[int]string{
+	API_Report_Queued:    "Report queued",
+	API_Report_Forbidden: "Reports are not available for this user",
+	API_Report_Not_Found: "Report not found",
+	API_Report_Not_Ready: "Report is not ready",
+}
@@ -0,0 +1,50 @@
+package apperrors
+
+import (
+	"backend/internal/apicodes"
+
+	"github.com/pkg/errors"
+)
+
+type AccountDeletionInvalidCredentialsError struct {
+	AppError
+}
+type AccountDeletionAlreadyScheduledError struct {
+	AppError
+}
+
+func (e *AccountDeletionInvalidCredentialsError) Error() string {
+	return e.Description
+}
+
+func (e *AccountDeletionAlreadyScheduledError) Error() string {
+	return e.Description
+}
+
+func NewAccountDeletionInvalidCredentialsError(desc string) *AccountDeletionInvalidCredentialsError {
+	return &AccountDeletionInvalidCredentialsError{
+		AppError: AppError{
+			Code:        apicodes.API_Account_Deletion_Invalid_Credentials,
+			Description: desc,
+		},
+	}
+}
+
+func NewAccountDeletionAlreadyScheduledError(desc string) *AccountDeletionAlreadyScheduledError {
+	return &AccountDeletionAlreadyScheduledError{
+		AppError: AppError{
+			Code:        apicodes.API_Account_Deletion_Already_Scheduled,
+			Description: desc,
+		},
+	}
+}
+
+func IsAccountDeletionInvalidCredentialsError(err error) bool {
+	var credErr *AccountDeletionInvalidCredentialsError
+	return errors.As(err, &credErr)
+}
+
+func IsAccountDeletionAlreadyScheduledError(err error) bool {
+	var scheduledErr *AccountDeletionAlreadyScheduledError
+	return errors.As(err, &scheduledErr)
+}
@@ -0,0 +1,50 @@
+package apperrors
+
+import (
+	"backend/internal/apicodes"
+
+	"github.com/pkg/errors"
+)
+
+type ConfirmationResendNotFoundError struct {
+	AppError
+}
+type ConfirmationResendTooManyRequestsError struct {
+	AppError
+}
+
+func (e *ConfirmationResendNotFoundError) Error() string {
+	return e.Description
+}
+
+func (e *ConfirmationResendTooManyRequestsError) Error() string {
+	return e.Description
+}
+
+func NewConfirmationResendNotFoundError(desc string) *ConfirmationResendNotFoundError {
+	return &ConfirmationResendNotFoundError{
+		AppError: AppError{
+			Code:        apicodes.API_Confirmation_Resend_Not_Found,
+			Description: desc,
+		},
+	}
+}
+
+func NewConfirmationResendTooManyRequestsError(desc string) *ConfirmationResendTooManyRequestsError {
+	return &ConfirmationResendTooManyRequestsError{
+		AppError: AppError{
+			Code:        apicodes.API_Confirmation_Resend_Too_Many_Requests,
+			Description: desc,
+		},
+	}
+}
+
+func IsConfirmationResendNotFoundError(err error) bool {
+	var notFoundErr *ConfirmationResendNotFoundError
+	return errors.As(err, &notFoundErr)
+}
+
+func IsConfirmationResendTooManyRequestsError(err error) bool {
+	var tooManyErr *ConfirmationResendTooManyRequestsError
+	return errors.As(err, &tooManyErr)
+}
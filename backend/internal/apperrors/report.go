@@ -0,0 +1,71 @@
+package apperrors
+
+import (
+	"backend/internal/apicodes"
+
+	"github.com/pkg/errors"
+)
+
+type ReportForbiddenError struct {
+	AppError
+}
+type ReportNotFoundError struct {
+	AppError
+}
+type ReportNotReadyError struct {
+	AppError
+}
+
+func (e *ReportForbiddenError) Error() string {
+	return e.Description
+}
+
+func (e *ReportNotFoundError) Error() string {
+	return e.Description
+}
+
+func (e *ReportNotReadyError) Error() string {
+	return e.Description
+}
+
+func NewReportForbiddenError(desc string) *ReportForbiddenError {
+	return &ReportForbiddenError{
+		AppError: AppError{
+			Code:        apicodes.API_Report_Forbidden,
+			Description: desc,
+		},
+	}
+}
+
+func NewReportNotFoundError(desc string) *ReportNotFoundError {
+	return &ReportNotFoundError{
+		AppError: AppError{
+			Code:        apicodes.API_Report_Not_Found,
+			Description: desc,
+		},
+	}
+}
+
+func NewReportNotReadyError(desc string) *ReportNotReadyError {
+	return &ReportNotReadyError{
+		AppError: AppError{
+			Code:        apicodes.API_Report_Not_Ready,
+			Description: desc,
+		},
+	}
+}
+
+func IsReportForbiddenError(err error) bool {
+	var forbiddenErr *ReportForbiddenError
+	return errors.As(err, &forbiddenErr)
+}
+
+func IsReportNotFoundError(err error) bool {
+	var notFoundErr *ReportNotFoundError
+	return errors.As(err, &notFoundErr)
+}
+
+func IsReportNotReadyError(err error) bool {
+	var notReadyErr *ReportNotReadyError
+	return errors.As(err, &notReadyErr)
+}
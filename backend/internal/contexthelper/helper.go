@@ -2,12 +2,16 @@ package contexthelper
 
 import (
 	"backend/config"
+	"backend/internal/featureflag"
 	"backend/pkg/logger"
 	"context"
 	"database/sql"
 	"log"
+	"log/slog"
 
-	amqp "github.com/rabbitmq/amqp091-go"
+	"backend/pkg/rabbitmq"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 type AccessTokenData struct {
@@ -38,6 +42,22 @@ func SetUserId(ctx context.Context, userID uint) context.Context {
 	return context.WithValue(ctx, userIdCtxKey, userID)
 }
 
+// LogAttrs returns the request ID, the user ID and the trace of the context, which the
+// logger adds to every record.
+func LogAttrs(ctx context.Context) []slog.Attr {
+	var attrs []slog.Attr
+	if requestID := GetRequestID(ctx); requestID != "" {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+	if userID, ok := GetUserId(ctx); ok {
+		attrs = append(attrs, slog.Any("user_id", userID))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		attrs = append(attrs, slog.String("trace_id", spanContext.TraceID().String()), slog.String("span_id", spanContext.SpanID().String()))
+	}
+	return attrs
+}
+
 func SetClientIp(ctx context.Context, ip string) context.Context {
 	return context.WithValue(ctx, clientIpCtxKey, ip)
 }
@@ -73,7 +93,19 @@ func GetConfig(ctx context.Context) *config.Config {
 	return nil
 }
 
-func SetServices(ctx context.Context, db *sql.DB, rabbitConn *amqp.Connection) context.Context {
+func SetFeatureFlags(ctx context.Context, flags *featureflag.Store) context.Context {
+	return context.WithValue(ctx, featureFlagsCtxKey, flags)
+}
+
+func GetFeatureFlags(ctx context.Context) *featureflag.Store {
+	if flags, ok := ctx.Value(featureFlagsCtxKey).(*featureflag.Store); ok {
+		return flags
+	}
+	logger.ErrorCtx(ctx, "There is no feature flag store in context")
+	return nil
+}
+
+func SetServices(ctx context.Context, db *sql.DB, rabbitConn *rabbitmq.Connection) context.Context {
 	ctx = SetDb(ctx, db)
 	return SetRabbitConn(ctx, rabbitConn)
 }
@@ -82,7 +114,7 @@ func SetDb(ctx context.Context, db *sql.DB) context.Context {
 	return context.WithValue(ctx, dbCtxKey, db)
 }
 
-func SetRabbitConn(ctx context.Context, conn *amqp.Connection) context.Context {
+func SetRabbitConn(ctx context.Context, conn *rabbitmq.Connection) context.Context {
 	return context.WithValue(ctx, rabbitCtxKey, conn)
 }
 
@@ -93,8 +125,8 @@ func GetDb(ctx context.Context) *sql.DB {
 	logger.ErrorCtx(ctx, "There is no db in context")
 	return nil
 }
-func GetRabbitConn(ctx context.Context) *amqp.Connection {
-	if rabbitConn, ok := ctx.Value(rabbitCtxKey).(*amqp.Connection); ok {
+func GetRabbitConn(ctx context.Context) *rabbitmq.Connection {
+	if rabbitConn, ok := ctx.Value(rabbitCtxKey).(*rabbitmq.Connection); ok {
 		log.Printf("get Rabbit Connection")
 		return rabbitConn
 	}
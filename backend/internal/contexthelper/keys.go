@@ -7,6 +7,7 @@ type clientIpKeyType struct{}
 type dbKeyType struct{}
 type rabbitKeyType struct{}
 type accessTokenDataCtxKeyType struct{}
+type featureFlagsKeyType struct{}
 
 var (
 	requestIdCtxKey       = requestIdKeyType{}
@@ -16,4 +17,5 @@ var (
 	dbCtxKey              = dbKeyType{}
 	rabbitCtxKey          = rabbitKeyType{}
 	accessTokenDataCtxKey = accessTokenDataCtxKeyType{}
+	featureFlagsCtxKey    = featureFlagsKeyType{}
 )
@@ -40,7 +40,7 @@ func deleteCookie(ctx context.Context, w http.ResponseWriter, cookieKey string)
 func GetUserIdFromJwtToken(r *http.Request) (uint, error) {
 	ctx := r.Context()
 	cookieToken := GetAccessToken(r)
-	logger.DebugCtx(ctx, "Received token: %s", cookieToken)
+	logger.DebugCtx(ctx, "Received token: %s", logger.Secret(cookieToken))
 	if cookieToken == "" {
 		err := fmt.Errorf("Access token is missing")
 		return 0, err
@@ -103,7 +103,7 @@ func SetAccessToken(ctx context.Context, w http.ResponseWriter, userId uint) {
 		SameSite: http.SameSiteLaxMode,
 		//MaxAge:   300, // 5 minut
 	})
-	logger.DebugCtx(ctx, "Set access token cookie value", token)
+	logger.DebugCtx(ctx, "Set access token cookie value: %s", logger.Secret(token))
 }
 func SetRefreshToken(w http.ResponseWriter, ctx context.Context, token string, maxAge int) {
 	cfg := contexthelper.GetConfig(ctx)
@@ -116,13 +116,13 @@ func SetRefreshToken(w http.ResponseWriter, ctx context.Context, token string, m
 		SameSite: http.SameSiteLaxMode,
 		MaxAge:   maxAge, // 5 minut
 	})
-	logger.DebugCtx(ctx, "Set refresh token cookie value", token)
+	logger.DebugCtx(ctx, "Set refresh token cookie value: %s", logger.Secret(token))
 }
 
 func generateJWT(ctx context.Context, userID uint) (string, error) {
 	cfg := contexthelper.GetConfig(ctx)
 	ttl := time.Minute * time.Duration(int64(cfg.Token.AccessTokenTtlMinutes))
-	logger.DebugCtx(ctx, "minutes:", cfg.Token.AccessTokenTtlMinutes, "ttl:", ttl, "expires:", jwt.NewNumericDate(time.Now().Add(ttl)))
+	logger.DebugCtx(ctx, "minutes: %d, ttl: %v, expires: %v", cfg.Token.AccessTokenTtlMinutes, ttl, jwt.NewNumericDate(time.Now().Add(ttl)))
 	claims := &Claims{
 		UserID: userID,
 		RegisteredClaims: jwt.RegisteredClaims{
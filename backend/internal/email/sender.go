@@ -3,6 +3,8 @@ package email
 import (
 	"backend/assets"
 	"backend/internal/contexthelper"
+	"backend/internal/metrics"
+	"backend/internal/tracing"
 	"backend/locale"
 	"backend/pkg/logger"
 	"bytes"
@@ -17,6 +19,8 @@ import (
 
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	"github.com/pkg/errors"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 	mail "gopkg.in/mail.v2"
 )
 
@@ -106,7 +110,7 @@ func (es *EmailSender) SendHtmlEmail(ctx context.Context, to, subject, htmlConte
 		defer os.Remove(logoPath)
 	}
 
-	logger.Info("Sending email to %s with subject: %s", to, subject)
+	logger.Info("Sending email to %s with subject: %s", logger.Email(to), subject)
 	m.SetBody("text/html", htmlBody.String())
 	//logger.Info("Email content:\n%s", htmlBody.String())
 	for _, img := range es.embedImages {
@@ -125,11 +129,19 @@ func (es *EmailSender) SendHtmlEmail(ctx context.Context, to, subject, htmlConte
 	//} else {
 	//	logger.Info("Cała wiadomość do wysłania:\n%s", buf.String())
 	//}
-	return d.DialAndSend(m)
+	_, span := tracing.Tracer().Start(ctx, "smtp send", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		semconv.ServerAddress(es.smtpHost),
+		semconv.ServerPort(es.smtpPort),
+	))
+	start := time.Now()
+	err = d.DialAndSend(m)
+	metrics.ObserveEmailSend(start, err)
+	tracing.End(span, err)
+	return err
 }
 
 func (es *EmailSender) SendWelcomeEmail(ctx context.Context, to, userName, langCode, confirmationLink string) error {
-	logger.InfoCtx(ctx, "Preparing to send welcome email to %s in language %s", to, langCode)
+	logger.InfoCtx(ctx, "Preparing to send welcome email to %s in language %s", logger.Email(to), langCode)
 	loc := locale.GetNewLocalizer(langCode)
 	// Fallback do EN jeśli brak tłumaczenia
 
@@ -234,6 +246,107 @@ func (es *EmailSender) SendPasswordResetEmail(ctx context.Context, to, userName,
 	}
 	return nil
 }
+func (es *EmailSender) SendAccountDeletionEmail(ctx context.Context, to, userName, langCode, cancelLink string, scheduledFor time.Time) error {
+	loc := locale.GetNewLocalizer(langCode)
+
+	// Wygeneruj HTML z szablonu
+	tmpl, err := template.ParseFS(templateFiles, "templates/account_deletion.html")
+	if err != nil {
+		return errors.Wrap(err, "parse account deletion email template")
+	}
+	cfg := contexthelper.GetConfig(ctx)
+	var htmlContent bytes.Buffer
+	err = tmpl.Execute(&htmlContent, map[string]interface{}{
+		"Hello":          template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "general.hello_user", TemplateData: map[string]string{"UserName": userName}})),
+		"YouRequested":   template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "account_deletion.you_requested"})),
+		"ScheduledInfo":  template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "account_deletion.scheduled_info", TemplateData: map[string]string{"DeletionDate": scheduledFor.Format("2006-01-02 15:04")}})),
+		"IfChangedMind":  template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "account_deletion.if_changed_mind"})),
+		"CancelLink":     cancelLink,
+		"CancelDeletion": template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "account_deletion.cancel_deletion"})),
+		"IfButtonFails":  template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "general.if_button_fails"})),
+		"IfNotYou":       template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "account_deletion.if_not_you"})),
+		"BestRegards":    template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "general.best_regards", TemplateData: map[string]string{"AppName": cfg.AppName}})),
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "execute account deletion email template")
+	}
+
+	subject := loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "account_deletion.subject", TemplateData: map[string]string{"AppName": cfg.AppName}})
+	pageTitle := loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "account_deletion.page_title"})
+	pageHeader := loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "account_deletion.page_header", TemplateData: map[string]string{"AppName": cfg.AppName}})
+	if err := es.SendHtmlEmail(ctx, to, subject, htmlContent.String(), pageTitle, pageHeader, ""); err != nil {
+		return errors.Wrap(err, "send email")
+	}
+	return nil
+}
+func (es *EmailSender) SendDataExportEmail(ctx context.Context, to, userName, langCode, downloadLink string, expiresAt time.Time) error {
+	loc := locale.GetNewLocalizer(langCode)
+
+	// Wygeneruj HTML z szablonu
+	tmpl, err := template.ParseFS(templateFiles, "templates/data_export.html")
+	if err != nil {
+		return errors.Wrap(err, "parse data export email template")
+	}
+	cfg := contexthelper.GetConfig(ctx)
+	var htmlContent bytes.Buffer
+	err = tmpl.Execute(&htmlContent, map[string]interface{}{
+		"Hello":          template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "general.hello_user", TemplateData: map[string]string{"UserName": userName}})),
+		"ExportReady":    template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "data_export.export_ready"})),
+		"PleaseDownload": template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "data_export.please_download"})),
+		"DownloadLink":   downloadLink,
+		"DownloadData":   template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "data_export.download_data"})),
+		"IfButtonFails":  template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "general.if_button_fails"})),
+		"LinkExpiryInfo": template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "data_export.link_expiry_info", TemplateData: map[string]string{"ExpiresAt": expiresAt.Format("2006-01-02 15:04")}})),
+		"IfNotYou":       template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "data_export.if_not_you"})),
+		"BestRegards":    template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "general.best_regards", TemplateData: map[string]string{"AppName": cfg.AppName}})),
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "execute data export email template")
+	}
+
+	subject := loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "data_export.subject", TemplateData: map[string]string{"AppName": cfg.AppName}})
+	pageTitle := loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "data_export.page_title"})
+	pageHeader := loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "data_export.page_header", TemplateData: map[string]string{"AppName": cfg.AppName}})
+	if err := es.SendHtmlEmail(ctx, to, subject, htmlContent.String(), pageTitle, pageHeader, ""); err != nil {
+		return errors.Wrap(err, "send email")
+	}
+	return nil
+}
+func (es *EmailSender) SendReportReadyEmail(ctx context.Context, to, userName, langCode, reportType, downloadLink string) error {
+	loc := locale.GetNewLocalizer(langCode)
+
+	// Wygeneruj HTML z szablonu
+	tmpl, err := template.ParseFS(templateFiles, "templates/report_ready.html")
+	if err != nil {
+		return errors.Wrap(err, "parse report ready email template")
+	}
+	cfg := contexthelper.GetConfig(ctx)
+	var htmlContent bytes.Buffer
+	err = tmpl.Execute(&htmlContent, map[string]interface{}{
+		"Hello":          template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "general.hello_user", TemplateData: map[string]string{"UserName": userName}})),
+		"ReportReady":    template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "report_ready.report_ready", TemplateData: map[string]string{"ReportType": reportType}})),
+		"PleaseDownload": template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "report_ready.please_download"})),
+		"DownloadLink":   downloadLink,
+		"DownloadReport": template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "report_ready.download_report"})),
+		"IfButtonFails":  template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "general.if_button_fails"})),
+		"LoginRequired":  template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "report_ready.login_required"})),
+		"BestRegards":    template.HTML(loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "general.best_regards", TemplateData: map[string]string{"AppName": cfg.AppName}})),
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "execute report ready email template")
+	}
+
+	subject := loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "report_ready.subject", TemplateData: map[string]string{"AppName": cfg.AppName}})
+	pageTitle := loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "report_ready.page_title"})
+	pageHeader := loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "report_ready.page_header", TemplateData: map[string]string{"AppName": cfg.AppName}})
+	if err := es.SendHtmlEmail(ctx, to, subject, htmlContent.String(), pageTitle, pageHeader, ""); err != nil {
+		return errors.Wrap(err, "send email")
+	}
+	return nil
+}
 func (es *EmailSender) AddEmbeddedImageFromBytes(contentID, contentType, fileName string, data []byte) (string, error) {
 	tempPath := filepath.Join(os.TempDir(), fmt.Sprintf("%s_%d", fileName, time.Now().UnixNano()))
 	if err := os.WriteFile(tempPath, data, 0644); err != nil {
@@ -0,0 +1,215 @@
+package featureflag
+
+import (
+	"backend/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/pkg/logger"
+	"cmp"
+	"context"
+	"errors"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Flags known to the application. Only these can be set through the admin endpoints.
+const (
+	Register      = "register"
+	ResetPassword = "reset_password"
+)
+
+const defaultRefreshInterval = 30 * time.Second
+
+var ErrUnknownFlag = errors.New("unknown feature flag")
+
+// Flag is the state of a feature flag as used by the application.
+type Flag struct {
+	Name           string                       `json:"name"`
+	Enabled        bool                         `json:"enabled"`
+	RolloutPercent int                          `json:"rollout_percent"`
+	Source         string                       `json:"source"`
+	Overrides      []models.FeatureFlagOverride `json:"overrides"`
+}
+
+// Sources of Flag.
+const (
+	SourceConfig   = "config"
+	SourceDatabase = "database"
+)
+
+// Store keeps the feature flags of the database in memory and refreshes them periodically,
+// so checking a flag does not query the database. Flags missing in the database use their
+// default from the config.
+type Store struct {
+	db       repository.DBExecutor
+	defaults map[string]bool
+
+	mu        sync.RWMutex
+	flags     map[string]models.FeatureFlag
+	overrides map[string]map[uint]bool
+}
+
+// NewStore returns a store of the flags in db. Until the first Refresh every flag has its
+// default value.
+func NewStore(db repository.DBExecutor, defaults map[string]bool) *Store {
+	return &Store{
+		db:        db,
+		defaults:  defaults,
+		flags:     map[string]models.FeatureFlag{},
+		overrides: map[string]map[uint]bool{},
+	}
+}
+
+// Defaults returns the values of the known flags in the config.
+func Defaults(cfg *config.Config) map[string]bool {
+	return map[string]bool{
+		Register:      cfg.Register.Enabled,
+		ResetPassword: cfg.ResetPassword.Enabled,
+	}
+}
+
+// Enabled reports whether the flag is on for the user; userId is 0 for anonymous requests.
+// A user override wins over the flag. A partial rollout enables the flag for the same
+// users on every check and for no anonymous request.
+func (s *Store) Enabled(name string, userId uint) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if enabled, ok := s.overrides[name][userId]; ok && userId != 0 {
+		return enabled
+	}
+	flag, ok := s.flags[name]
+	if !ok {
+		return s.defaults[name]
+	}
+	if !flag.Enabled {
+		return false
+	}
+	if flag.RolloutPercent >= 100 {
+		return true
+	}
+	return userId != 0 && bucket(name, userId) < flag.RolloutPercent
+}
+
+// bucket assigns the user a number from 0 to 99, different for every flag, so partial
+// rollouts of two flags do not hit the same users.
+func bucket(name string, userId uint) int {
+	h := fnv.New32a()
+	h.Write([]byte(name + ":" + strconv.FormatUint(uint64(userId), 10)))
+	return int(h.Sum32() % 100)
+}
+
+// Flags returns the state of the known flags.
+func (s *Store) Flags() []Flag {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var list []Flag
+	for _, name := range s.names() {
+		flag := Flag{Name: name, Enabled: s.defaults[name], RolloutPercent: 100, Source: SourceConfig, Overrides: []models.FeatureFlagOverride{}}
+		if dbFlag, ok := s.flags[name]; ok {
+			flag.Enabled, flag.RolloutPercent, flag.Source = dbFlag.Enabled, dbFlag.RolloutPercent, SourceDatabase
+		}
+		for userId, enabled := range s.overrides[name] {
+			flag.Overrides = append(flag.Overrides, models.FeatureFlagOverride{FlagName: name, UserId: userId, Enabled: enabled})
+		}
+		slices.SortFunc(flag.Overrides, func(a, b models.FeatureFlagOverride) int { return cmp.Compare(a.UserId, b.UserId) })
+		list = append(list, flag)
+	}
+	return list
+}
+
+func (s *Store) names() []string {
+	var names []string
+	for name := range s.defaults {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Refresh reloads the flags from the database. On error the previous flags are kept.
+func (s *Store) Refresh(ctx context.Context) error {
+	repo := repository.NewFeatureFlagRepository(s.db)
+	flags, err := repo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	overrides, err := repo.GetOverrides(ctx)
+	if err != nil {
+		return err
+	}
+
+	flagMap := make(map[string]models.FeatureFlag, len(flags))
+	for _, flag := range flags {
+		flagMap[flag.Name] = flag
+	}
+	overrideMap := map[string]map[uint]bool{}
+	for _, override := range overrides {
+		if overrideMap[override.FlagName] == nil {
+			overrideMap[override.FlagName] = map[uint]bool{}
+		}
+		overrideMap[override.FlagName][override.UserId] = override.Enabled
+	}
+
+	s.mu.Lock()
+	s.flags, s.overrides = flagMap, overrideMap
+	s.mu.Unlock()
+	return nil
+}
+
+// Run refreshes the flags every interval until the context is cancelled. Changes made
+// by other replicas are seen after at most one interval.
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultRefreshInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+				logger.ErrorCtx(ctx, "Failed to refresh feature flags: %v", err)
+			}
+		}
+	}
+}
+
+// Set stores the state of a known flag and applies it to this replica at once.
+func (s *Store) Set(ctx context.Context, name string, enabled bool, rolloutPercent int) error {
+	if _, ok := s.defaults[name]; !ok {
+		return ErrUnknownFlag
+	}
+	if err := repository.NewFeatureFlagRepository(s.db).Save(ctx, name, enabled, rolloutPercent); err != nil {
+		return err
+	}
+	return s.Refresh(ctx)
+}
+
+// SetOverride enables or disables a known flag for the user.
+func (s *Store) SetOverride(ctx context.Context, name string, userId uint, enabled bool) error {
+	if _, ok := s.defaults[name]; !ok {
+		return ErrUnknownFlag
+	}
+	if err := repository.NewFeatureFlagRepository(s.db).SaveOverride(ctx, name, userId, enabled); err != nil {
+		return err
+	}
+	return s.Refresh(ctx)
+}
+
+// DeleteOverride removes the override of a known flag for the user.
+func (s *Store) DeleteOverride(ctx context.Context, name string, userId uint) error {
+	if _, ok := s.defaults[name]; !ok {
+		return ErrUnknownFlag
+	}
+	if err := repository.NewFeatureFlagRepository(s.db).DeleteOverride(ctx, name, userId); err != nil {
+		return err
+	}
+	return s.Refresh(ctx)
+}
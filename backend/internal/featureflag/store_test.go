@@ -0,0 +1,115 @@
+package featureflag_test
+
+import (
+	"backend/internal/featureflag"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func expectFlags(mock sqlmock.Sqlmock, flags [][]driver.Value, overrides [][]driver.Value) {
+	flagRows := sqlmock.NewRows([]string{"name", "enabled", "rollout_percent", "updated_at"})
+	for _, flag := range flags {
+		flagRows.AddRow(append(flag, time.Now())...)
+	}
+	mock.ExpectQuery("SELECT name, enabled, rollout_percent, updated_at FROM feature_flags").WillReturnRows(flagRows)
+	overrideRows := sqlmock.NewRows([]string{"flag_name", "user_id", "enabled"})
+	for _, override := range overrides {
+		overrideRows.AddRow(override...)
+	}
+	mock.ExpectQuery("SELECT flag_name, user_id, enabled FROM feature_flag_overrides").WillReturnRows(overrideRows)
+}
+
+func TestStore_Enabled(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	expectFlags(mock,
+		[][]driver.Value{{featureflag.Register, false, 100}, {"beta", true, 0}},
+		[][]driver.Value{{featureflag.Register, 7, true}, {"beta", 8, true}},
+	)
+	store := featureflag.NewStore(db, map[string]bool{featureflag.Register: true, featureflag.ResetPassword: true, "beta": false})
+
+	if err := store.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		flag   string
+		userId uint
+		want   bool
+	}{
+		{"disabled in the database", featureflag.Register, 0, false},
+		{"user override", featureflag.Register, 7, true},
+		{"default from the config", featureflag.ResetPassword, 0, true},
+		{"zero rollout", "beta", 1, false},
+		{"zero rollout with override", "beta", 8, true},
+		{"unknown flag", "unknown", 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := store.Enabled(tt.flag, tt.userId); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestStore_PartialRollout(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	expectFlags(mock, [][]driver.Value{{featureflag.Register, true, 30}}, nil)
+	store := featureflag.NewStore(db, map[string]bool{featureflag.Register: true})
+	if err := store.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	enabled := 0
+	for userId := uint(1); userId <= 1000; userId++ {
+		if store.Enabled(featureflag.Register, userId) {
+			enabled++
+		}
+		if store.Enabled(featureflag.Register, userId) != store.Enabled(featureflag.Register, userId) {
+			t.Fatalf("rollout is not stable for user %d", userId)
+		}
+	}
+	if enabled < 250 || enabled > 350 {
+		t.Errorf("expected about 30%% of the users, got %d of 1000", enabled)
+	}
+	if store.Enabled(featureflag.Register, 0) {
+		t.Error("expected a partial rollout to be off for anonymous requests")
+	}
+}
+
+func TestStore_RefreshErrorKeepsFlags(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	expectFlags(mock, [][]driver.Value{{featureflag.Register, false, 100}}, nil)
+	mock.ExpectQuery("SELECT name, enabled, rollout_percent, updated_at FROM feature_flags").WillReturnError(errors.New("connection refused"))
+	store := featureflag.NewStore(db, map[string]bool{featureflag.Register: true})
+
+	if err := store.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if err := store.Refresh(context.Background()); err == nil {
+		t.Fatal("expected the second Refresh to fail")
+	}
+	if store.Enabled(featureflag.Register, 0) {
+		t.Error("expected the flag to stay disabled after a failed refresh")
+	}
+}
+
+func TestStore_SetUnknownFlag(t *testing.T) {
+	store := featureflag.NewStore(nil, map[string]bool{featureflag.Register: true})
+
+	if err := store.Set(context.Background(), "unknown", true, 100); !errors.Is(err, featureflag.ErrUnknownFlag) {
+		t.Errorf("expected ErrUnknownFlag, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"backend/internal/apperrors"
+	"backend/internal/contexthelper"
+	"backend/internal/cookie"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/response"
+	"backend/internal/service"
+	"backend/pkg/logger"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+type AccountDeletionRequest struct {
+	Password string `json:"password"`
+}
+
+func (h *Handler) DeleteMeHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId, ok := contexthelper.GetUserId(ctx)
+	if !ok {
+		response.UnauthorizedErrorResponse(w, "User not found")
+		return
+	}
+	var req AccountDeletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.InvalidJsonErrorResponse(w)
+		return
+	}
+	if req.Password == "" {
+		response.InvalidInputValueErrorResponse(w, "password", "password field is required")
+		return
+	}
+
+	db := contexthelper.GetDb(ctx)
+	ctRepo := repository.NewConfirmationTokenRepository(db)
+	uRepo := repository.NewUserRepository(db)
+	sessionRepo := repository.NewUserSessionsRepository(db)
+
+	service := service.NewAccountDeletionService(ctRepo, uRepo, sessionRepo)
+	scheduledFor, err := service.ScheduleDeletion(ctx, userId, req.Password)
+	if err != nil {
+		if apperrors.IsAccountDeletionInvalidCredentialsError(err) {
+			logger.InfoCtx(ctx, "Account deletion rejected for user %d: invalid credentials", userId)
+			response.AccountDeletionErrorInvalidCredentials(w)
+		} else if apperrors.IsAccountDeletionAlreadyScheduledError(err) {
+			response.AccountDeletionErrorAlreadyScheduled(w)
+		} else {
+			logger.ErrorCtx(ctx, "Failed to schedule account deletion: %v", err)
+			response.InternalServerError(w)
+		}
+		return
+	}
+	logger.InfoCtx(ctx, "Account deletion of user %d scheduled for %v", userId, scheduledFor)
+
+	// Konto czeka na usunięcie – wylogowujemy użytkownika
+	accessTokenData, ctx := contexthelper.GetAccessTokenData(ctx)
+	accessTokenData.SetCookies = false
+	accessTokenData.RefreshToken = ""
+	cookie.DeleteAccessToken(ctx, w)
+	cookie.DeleteRefreshToken(ctx, w)
+
+	response.SetAccountDeletionScheduledResponse(w, ctx, scheduledFor)
+}
+
+func (h *Handler) confirmAccountDeletionCancelHandler(ctx context.Context, db repository.DBExecutor, ct models.ConfirmationToken) error {
+	ctRepo := repository.NewConfirmationTokenRepository(db)
+	uRepo := repository.NewUserRepository(db)
+	sessionRepo := repository.NewUserSessionsRepository(db)
+	service := service.NewAccountDeletionService(ctRepo, uRepo, sessionRepo)
+	err := service.CancelDeletion(ctx, ct.UserId)
+	if err != nil {
+		return errors.Wrap(err, "failed to cancel account deletion")
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package handler_test
+
+import (
+	"backend/config"
+	"backend/internal/handler"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func withAccountDeletion(cfg *config.Config) {
+	cfg.AccountDeletion.GracePeriodDays = 14
+}
+
+func TestDeleteMeHandler_Unauthorized(t *testing.T) {
+	h := handler.NewHandler()
+
+	body, _ := json.Marshal(map[string]string{"password": "Test123!@#"})
+	req, rr := NewTestRequest(
+		http.MethodDelete,
+		"/me",
+		bytes.NewBuffer(body),
+		TestDeps{},
+	)
+
+	h.DeleteMeHandler(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d", rr.Code)
+	}
+}
+
+func TestDeleteMeHandler_InvalidJSON(t *testing.T) {
+	h := handler.NewHandler()
+
+	req, rr := NewTestRequest(
+		http.MethodDelete,
+		"/me",
+		bytes.NewBufferString("invalid json"),
+		TestDeps{UserID: 1},
+	)
+
+	h.DeleteMeHandler(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
+
+func TestDeleteMeHandler_EmptyPassword(t *testing.T) {
+	h := handler.NewHandler()
+
+	body, _ := json.Marshal(map[string]string{"password": ""})
+	req, rr := NewTestRequest(
+		http.MethodDelete,
+		"/me",
+		bytes.NewBuffer(body),
+		TestDeps{UserID: 1},
+	)
+
+	h.DeleteMeHandler(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
+
+func TestDeleteMeHandler_InvalidPassword(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	h := handler.NewHandler()
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("Correct123!@#"), bcrypt.DefaultCost)
+	mock.ExpectQuery("SELECT.*FROM users WHERE id").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name", "email", "password", "registered_at", "confirmed_at"}).
+			AddRow(1, "testuser", "test@example.com", string(hashedPassword), time.Now(), time.Now()),
+	)
+
+	body, _ := json.Marshal(map[string]string{"password": "Wrong123!@#"})
+	req, rr := NewTestRequest(
+		http.MethodDelete,
+		"/me",
+		bytes.NewBuffer(body),
+		TestDeps{DB: db, UserID: 1, Config: testConfig(withAccountDeletion)},
+	)
+
+	h.DeleteMeHandler(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d", rr.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteMeHandler_AlreadyScheduled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	h := handler.NewHandler()
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("Test123!@#"), bcrypt.DefaultCost)
+	mock.ExpectQuery("SELECT.*FROM users WHERE id").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name", "email", "password", "registered_at", "confirmed_at"}).
+			AddRow(1, "testuser", "test@example.com", string(hashedPassword), time.Now(), time.Now()),
+	)
+	// Deletion already scheduled - no rows affected
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users SET deletion_scheduled_at").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	body, _ := json.Marshal(map[string]string{"password": "Test123!@#"})
+	req, rr := NewTestRequest(
+		http.MethodDelete,
+		"/me",
+		bytes.NewBuffer(body),
+		TestDeps{DB: db, UserID: 1, Config: testConfig(withAccountDeletion)},
+	)
+
+	h.DeleteMeHandler(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected status 409, got %d", rr.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteMeHandler_UserNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	h := handler.NewHandler()
+
+	mock.ExpectQuery("SELECT.*FROM users WHERE id").WillReturnError(sql.ErrNoRows)
+
+	body, _ := json.Marshal(map[string]string{"password": "Test123!@#"})
+	req, rr := NewTestRequest(
+		http.MethodDelete,
+		"/me",
+		bytes.NewBuffer(body),
+		TestDeps{DB: db, UserID: 1, Config: testConfig(withAccountDeletion)},
+	)
+
+	h.DeleteMeHandler(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", rr.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"backend/config"
 	"backend/internal/contexthelper"
+	"backend/internal/featureflag"
 	"backend/internal/repository"
 	"backend/internal/response"
 	"backend/internal/service"
@@ -25,9 +26,12 @@ func (h *Handler) CfgHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Registration and password reset are anonymous, so their endpoints check the flags
+	// without a user; report them the same way, ignoring overrides and partial rollouts
+	flags := contexthelper.GetFeatureFlags(ctx)
 	featuresConfig := config.FeaturesConfig{
-		Register:      cfg.Register.Enabled,
-		ResetPassword: cfg.ResetPassword.Enabled,
+		Register:      flags.Enabled(featureflag.Register, 0),
+		ResetPassword: flags.Enabled(featureflag.ResetPassword, 0),
 	}
 
 	cfgData := response.CfgResponseData{
@@ -19,40 +19,57 @@ import (
 func (h *Handler) ConfirmHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	token := chi.URLParam(r, "token")
-	logger.InfoCtx(ctx, "ConfirmHandler called with token: %s", token)
+	logger.InfoCtx(ctx, "ConfirmHandler called with token: %s", logger.Secret(token))
 	if token == "" {
 		logger.WarnCtx(ctx, "Empty confirmation token provided")
 		response.NotFoundErrorResponse(w)
 		return
 	}
 	db := contexthelper.GetDb(ctx)
-
-	ctRepo := repository.NewConfirmationTokenRepository(db)
-	if ctRepo == nil {
+	// Claiming, confirming and consuming the token happen in one transaction, so a token
+	// confirmed twice at the same time is applied once
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to begin transaction: %v", err)
 		response.InternalServerError(w)
 		return
 	}
-	ct, err := ctRepo.GetActiveNewToken(ctx, token)
-	if err != nil || ct.Id == 0 {
-		logger.WarnCtx(ctx, "No active confirmation token found", "token", token, "error", err)
+	defer tx.Rollback()
+
+	ctRepo := repository.NewConfirmationTokenRepository(tx)
+	ct, err := ctRepo.ClaimToken(ctx, token)
+	if errors.Is(err, repository.ErrConfirmationTokenConsumed) {
+		logger.InfoCtx(ctx, "Confirmation token already used")
+		response.SetConfirmAlreadyUsedResponse(w)
+		return
+	}
+	if err != nil {
+		logger.WarnCtx(ctx, "No active confirmation token found: %v", err)
 		response.NotFoundErrorResponse(w)
 		return
 	}
 	switch ct.Type {
 	case models.ConfirmationTokenTypeRegister:
-		err := h.confirmRegisterHandler(ctx, ct)
+		err := h.confirmRegisterHandler(ctx, tx, ct)
 		if err != nil {
 			logger.ErrorCtx(ctx, "Failed to confirm registration token: %v", err)
 			response.InternalServerError(w)
 			return
 		}
 	case models.ConfirmationTokenTypeEmailChange:
-		err := h.confirmEmailChangeHandler(ctx, ct)
+		err := h.confirmEmailChangeHandler(ctx, tx, ct)
 		if err != nil {
 			logger.ErrorCtx(ctx, "Failed to confirm email change token: %v", err)
 			response.InternalServerError(w)
 			return
 		}
+	case models.ConfirmationTokenTypeAccountDeletion:
+		err := h.confirmAccountDeletionCancelHandler(ctx, tx, ct)
+		if err != nil {
+			logger.ErrorCtx(ctx, "Failed to cancel account deletion: %v", err)
+			response.InternalServerError(w)
+			return
+		}
 	default:
 		logger.WarnCtx(ctx, "Unknown confirmation token type: %s", ct.Type)
 		response.BadRequestErrorResponse(w)
@@ -61,38 +78,28 @@ func (h *Handler) ConfirmHandler(w http.ResponseWriter, r *http.Request) {
 	err = ctRepo.ConsumeToken(ctx, token)
 	if err != nil {
 		logger.ErrorCtx(ctx, "Failed to consume confirmation token: %v", err)
+		response.InternalServerError(w)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		logger.ErrorCtx(ctx, "Failed to commit confirmation: %v", err)
+		response.InternalServerError(w)
 		return
 	}
 	response.SetConfirmSuccessResponse(w, ctx, ct.Type)
 }
 
-func (h *Handler) confirmRegisterHandler(ctx context.Context, ct models.ConfirmationToken) error {
-	db := contexthelper.GetDb(ctx)
-	tx, err := db.BeginTx(ctx, nil)
-	if err != nil {
-		return errors.Wrap(err, "failed to begin transaction")
-	}
-	uRepo := repository.NewUserRepository(tx)
-	usRepo := repository.NewUserSettingsRepository(tx)
+func (h *Handler) confirmRegisterHandler(ctx context.Context, db repository.DBExecutor, ct models.ConfirmationToken) error {
+	uRepo := repository.NewUserRepository(db)
+	usRepo := repository.NewUserSettingsRepository(db)
 	service := service.NewRegisterConfirmationService(uRepo, usRepo)
 	id, err := service.ConfirmRegisterToken(ctx, ct)
 	if err != nil || id == 0 {
-		if rbErr := tx.Rollback(); rbErr != nil {
-			logger.ErrorCtx(ctx, "Failed to rollback transaction: %v", rbErr)
-		}
 		return errors.Wrap(err, "failed to confirm registration token")
 	}
-	if err := tx.Commit(); err != nil {
-		logger.ErrorCtx(ctx, "Failed to commit transaction: %v", err)
-		if rbErr := tx.Rollback(); rbErr != nil {
-			logger.ErrorCtx(ctx, "Failed to rollback transaction: %v", rbErr)
-		}
-		return errors.Wrap(err, "failed to commit transaction")
-	}
-	return nil
+	return repository.NewConfirmationTokenRepository(db).AssignUser(ctx, ct.Id, id)
 }
-func (h *Handler) confirmEmailChangeHandler(ctx context.Context, ct models.ConfirmationToken) error {
-	db := contexthelper.GetDb(ctx)
+func (h *Handler) confirmEmailChangeHandler(ctx context.Context, db repository.DBExecutor, ct models.ConfirmationToken) error {
 	uRepo := repository.NewUserRepository(db)
 	service := service.NewUserService(uRepo)
 	err := service.ConfirmEmailChangeToken(ctx, ct)
@@ -101,8 +108,7 @@ func (h *Handler) confirmEmailChangeHandler(ctx context.Context, ct models.Confi
 	}
 	return nil
 }
-func (h *Handler) passwordChangeHandler(ctx context.Context, ct models.ConfirmationToken, newPassword string) error {
-	db := contexthelper.GetDb(ctx)
+func (h *Handler) passwordChangeHandler(ctx context.Context, db repository.DBExecutor, ct models.ConfirmationToken, newPassword string) error {
 	uRepo := repository.NewUserRepository(db)
 	ctRepo := repository.NewConfirmationTokenRepository(db)
 	service := service.NewPasswordService(ctRepo, uRepo)
@@ -3,7 +3,9 @@ package handler_test
 import (
 	"backend/internal/handler"
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"net/http"
 	"testing"
 	"time"
@@ -23,21 +25,40 @@ func TestConfirmHandler(t *testing.T) {
 			name:  "success",
 			token: "test-token",
 			mock: func(m sqlmock.Sqlmock) {
-				// Mock confirmation token lookup
-				m.ExpectQuery("SELECT.*FROM confirmation_tokens").WillReturnRows(
+				// Mock transaction begin
+				m.ExpectBegin()
+				// Mock confirmation token claim
+				m.ExpectQuery("SELECT.*FROM confirmation_tokens .*FOR UPDATE").WillReturnRows(
 					sqlmock.NewRows([]string{"id", "token", "user_id", "type", "payload", "status", "expires_at", "status_changed_at", "created_at"}).
 						AddRow(1, "test-token", 1, "register", "{}", "NEW", time.Now().Add(1*time.Hour), time.Now(), time.Now()),
 				)
-				// Mock transaction begin
-				m.ExpectBegin()
 				// Mock user insert (confirm registration creates new user)
 				m.ExpectExec("INSERT INTO users").WillReturnResult(sqlmock.NewResult(1, 1))
 				// Mock user settings insert
 				m.ExpectExec("INSERT INTO user_settings").WillReturnResult(sqlmock.NewResult(1, 1))
+				// Mock linking the register token to the new user
+				m.ExpectExec("UPDATE confirmation_tokens SET user_id").WithArgs(1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				// Mock token consume
+				m.ExpectExec("UPDATE confirmation_tokens").WillReturnResult(sqlmock.NewResult(0, 1))
 				// Mock transaction commit
 				m.ExpectCommit()
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:  "cancel account deletion",
+			token: "deletion-token",
+			mock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT.*FROM confirmation_tokens").WillReturnRows(
+					sqlmock.NewRows([]string{"id", "token", "user_id", "type", "payload", "status", "expires_at", "status_changed_at", "created_at"}).
+						AddRow(2, "deletion-token", 1, "account_deletion", "{}", "NEW", time.Now().Add(1*time.Hour), time.Now(), time.Now()),
+				)
+				// Mock deletion cancel
+				m.ExpectExec("UPDATE users SET deletion_scheduled_at = NULL").WillReturnResult(sqlmock.NewResult(0, 1))
 				// Mock token consume
 				m.ExpectExec("UPDATE confirmation_tokens").WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
 			},
 			wantStatus: http.StatusOK,
 		},
@@ -45,7 +66,36 @@ func TestConfirmHandler(t *testing.T) {
 			name:  "token not found",
 			token: "invalid-token",
 			mock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
 				m.ExpectQuery("SELECT.*FROM confirmation_tokens").WillReturnError(sql.ErrNoRows)
+				m.ExpectRollback()
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:  "token already used",
+			token: "used-token",
+			mock: func(m sqlmock.Sqlmock) {
+				// A concurrent confirmation consumed the token while this one waited for the lock
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT.*FROM confirmation_tokens .*FOR UPDATE").WillReturnRows(
+					sqlmock.NewRows([]string{"id", "token", "user_id", "type", "payload", "status", "expires_at", "status_changed_at", "created_at"}).
+						AddRow(3, "used-token", 0, "register", "{}", "CONSUMED", time.Now().Add(1*time.Hour), time.Now(), time.Now()),
+				)
+				m.ExpectRollback()
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:  "token looked up by its hash",
+			token: "plain-token",
+			mock: func(m sqlmock.Sqlmock) {
+				hash := sha256.Sum256([]byte("plain-token"))
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT.*FROM confirmation_tokens WHERE token = ?").
+					WithArgs(hex.EncodeToString(hash[:])).
+					WillReturnError(sql.ErrNoRows)
+				m.ExpectRollback()
 			},
 			wantStatus: http.StatusNotFound,
 		},
@@ -53,10 +103,12 @@ func TestConfirmHandler(t *testing.T) {
 			name:  "invalid token type",
 			token: "invalid-token",
 			mock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
 				m.ExpectQuery("SELECT.*FROM confirmation_tokens").WillReturnRows(
 					sqlmock.NewRows([]string{"id", "token", "user_id", "type", "payload", "status", "expires_at", "status_changed_at", "created_at"}).
 						AddRow(1, "invalid-token", 1, "invalid_type", "{}", "NEW", time.Now().Add(1*time.Hour), time.Now(), time.Now()),
 					)
+				m.ExpectRollback()
 			},
 			wantStatus: http.StatusBadRequest,
 		},
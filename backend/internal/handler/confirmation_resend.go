@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"backend/internal/apperrors"
+	"backend/internal/contexthelper"
+	"backend/internal/featureflag"
+	"backend/internal/repository"
+	"backend/internal/response"
+	"backend/internal/service"
+	"backend/pkg/logger"
+	"backend/pkg/validation"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+type RegisterResendRequest struct {
+	Email string `json:"email"`
+}
+
+func (h *Handler) RegisterResendHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !contexthelper.GetFeatureFlags(ctx).Enabled(featureflag.Register, 0) {
+		response.FeatureDisabledErrorResponse(w)
+		return
+	}
+	var req RegisterResendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.InvalidJsonErrorResponse(w)
+		return
+	}
+	email := strings.ToLower(req.Email)
+	if email == "" || !validation.IsEmailValid(email) {
+		response.InvalidInputValueErrorResponse(w, "email", "invalid email format")
+		return
+	}
+
+	db := contexthelper.GetDb(ctx)
+	service := service.NewRegisterService(repository.NewConfirmationTokenRepository(db), repository.NewUserRepository(db), repository.NewLanguageRepository(db))
+	err := service.ResendConfirmation(ctx, email)
+	if err != nil {
+		confirmationResendErrorResponse(w, r, err)
+		return
+	}
+	logger.InfoCtx(ctx, "Registration confirmation resent to %s", logger.Email(email))
+	response.ConfirmationResendSuccessResponse(w, ctx)
+}
+
+func (h *Handler) EmailChangeResendHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	db := contexthelper.GetDb(ctx)
+	service := service.NewEmailService(repository.NewConfirmationTokenRepository(db), repository.NewUserRepository(db), repository.NewLanguageRepository(db))
+	err := service.ResendEmailChange(ctx)
+	if err != nil {
+		confirmationResendErrorResponse(w, r, err)
+		return
+	}
+	logger.InfoCtx(ctx, "Email change confirmation resent")
+	response.ConfirmationResendSuccessResponse(w, ctx)
+}
+
+func confirmationResendErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	ctx := r.Context()
+	if apperrors.IsConfirmationResendNotFoundError(err) {
+		logger.InfoCtx(ctx, "No pending confirmation to resend")
+		response.ConfirmationResendErrorNotFound(w)
+	} else if apperrors.IsConfirmationResendTooManyRequestsError(err) {
+		logger.InfoCtx(ctx, "Confirmation resend rate limited")
+		response.ConfirmationResendErrorTooManyRequests(w)
+	} else {
+		logger.ErrorCtx(ctx, "Failed to resend confirmation: %v", err)
+		response.InternalServerError(w)
+	}
+}
@@ -0,0 +1,48 @@
+package handler_test
+
+import (
+	"backend/internal/handler"
+	"bytes"
+	"database/sql"
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestRegisterResendHandler_InvalidEmail(t *testing.T) {
+	h := handler.NewHandler()
+
+	req, rr := NewTestRequest(http.MethodPost, "/register/resend", bytes.NewBufferString(`{"email": "not-an-email"}`), TestDeps{})
+
+	h.RegisterResendHandler(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
+
+func TestEmailChangeResendHandler_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	h := handler.NewHandler()
+
+	mock.ExpectQuery(`SELECT .* FROM confirmation_tokens WHERE type = \?.* AND user_id = \?`).
+		WithArgs("email_change", 1).
+		WillReturnError(sql.ErrNoRows)
+
+	req, rr := NewTestRequest(http.MethodPost, "/email_change/resend", nil, TestDeps{DB: db, UserID: 1})
+
+	h.EmailChangeResendHandler(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rr.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"backend/internal/contexthelper"
+	"backend/internal/response"
+	"backend/internal/service"
+	"backend/pkg/logger"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) DataExportHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId, ok := contexthelper.GetUserId(ctx)
+	if !ok {
+		response.UnauthorizedErrorResponse(w, "User not found")
+		return
+	}
+
+	service := service.NewDataExportService()
+	err := service.RequestExport(ctx, userId)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to request data export: %v", err)
+		response.InternalServerError(w)
+		return
+	}
+	logger.InfoCtx(ctx, "Data export requested by user %d", userId)
+	response.DataExportRequestedResponse(w, ctx)
+}
+
+func (h *Handler) DataExportDownloadHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	fileName := chi.URLParam(r, "file")
+
+	service := service.NewDataExportService()
+	path, err := service.GetExportFilePath(ctx, fileName, r.URL.Query())
+	if err != nil {
+		logger.WarnCtx(ctx, "Data export download rejected: %v", err)
+		response.NotFoundErrorResponse(w)
+		return
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	w.Header().Set("Content-Type", "application/zip")
+	http.ServeFile(w, r, path)
+}
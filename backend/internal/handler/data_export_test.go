@@ -0,0 +1,124 @@
+package handler_test
+
+import (
+	"backend/config"
+	"backend/internal/handler"
+	"backend/pkg/signedlink"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testExportFileName = "export_1_abc123.zip"
+
+// withDataExport stores the data exports in a temporary directory holding testExportFileName.
+func withDataExport(t *testing.T) func(cfg *config.Config) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, testExportFileName), []byte("zip"), 0640); err != nil {
+		t.Fatalf("failed to create export file: %v", err)
+	}
+	return func(cfg *config.Config) {
+		cfg.Token.JwtSecret = "test-secret"
+		cfg.DataExport = config.DataExportConfig{
+			StorageDir:   dir,
+			LinkTtlHours: 48,
+		}
+	}
+}
+
+func TestDataExportHandler_Unauthorized(t *testing.T) {
+	h := handler.NewHandler()
+
+	req, rr := NewTestRequest(http.MethodPost, "/me/export", nil, TestDeps{})
+
+	h.DataExportHandler(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d", rr.Code)
+	}
+}
+
+func TestDataExportDownloadHandler_Success(t *testing.T) {
+	h := handler.NewHandler()
+	cfg := testConfig(withDataExport(t))
+
+	query := signedlink.Sign(cfg.Token.JwtSecret, testExportFileName, time.Now().Add(time.Hour))
+	req, rr := NewTestRequest(
+		http.MethodGet,
+		"/export/"+testExportFileName+"?"+query.Encode(),
+		nil,
+		TestDeps{Config: cfg, URLParams: map[string]string{"file": testExportFileName}},
+	)
+
+	h.DataExportDownloadHandler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if rr.Body.String() != "zip" {
+		t.Errorf("unexpected body %q", rr.Body.String())
+	}
+	if got := rr.Header().Get("Content-Disposition"); got == "" {
+		t.Error("expected Content-Disposition header")
+	}
+}
+
+func TestDataExportDownloadHandler_InvalidSignature(t *testing.T) {
+	h := handler.NewHandler()
+	cfg := testConfig(withDataExport(t))
+
+	query := signedlink.Sign("other-secret", testExportFileName, time.Now().Add(time.Hour))
+	req, rr := NewTestRequest(
+		http.MethodGet,
+		"/export/"+testExportFileName+"?"+query.Encode(),
+		nil,
+		TestDeps{Config: cfg, URLParams: map[string]string{"file": testExportFileName}},
+	)
+
+	h.DataExportDownloadHandler(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rr.Code)
+	}
+}
+
+func TestDataExportDownloadHandler_Expired(t *testing.T) {
+	h := handler.NewHandler()
+	cfg := testConfig(withDataExport(t))
+
+	query := signedlink.Sign(cfg.Token.JwtSecret, testExportFileName, time.Now().Add(-time.Minute))
+	req, rr := NewTestRequest(
+		http.MethodGet,
+		"/export/"+testExportFileName+"?"+query.Encode(),
+		nil,
+		TestDeps{Config: cfg, URLParams: map[string]string{"file": testExportFileName}},
+	)
+
+	h.DataExportDownloadHandler(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rr.Code)
+	}
+}
+
+func TestDataExportDownloadHandler_InvalidFileName(t *testing.T) {
+	h := handler.NewHandler()
+	cfg := testConfig(withDataExport(t))
+
+	fileName := "../config.yaml"
+	query := signedlink.Sign(cfg.Token.JwtSecret, fileName, time.Now().Add(time.Hour))
+	req, rr := NewTestRequest(
+		http.MethodGet,
+		"/export/"+fileName+"?"+query.Encode(),
+		nil,
+		TestDeps{Config: cfg, URLParams: map[string]string{"file": fileName}},
+	)
+
+	h.DataExportDownloadHandler(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"backend/internal/response"
+	"backend/internal/service"
+	"backend/pkg/logger"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ReplayDeadLettersRequest struct {
+	Ids []string `json:"ids"`
+}
+
+func (h *Handler) ListDeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queueName := chi.URLParam(r, "queue")
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			response.InvalidInputValueErrorResponse(w, "limit", "limit must be a positive number")
+			return
+		}
+	}
+
+	service := service.NewDeadLetterService()
+	list, err := service.List(ctx, queueName, limit)
+	if err != nil {
+		writeDeadLetterError(w, r, err)
+		return
+	}
+	response.SetDeadLettersResponse(w, ctx, queueName, list)
+}
+
+func (h *Handler) ReplayDeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queueName := chi.URLParam(r, "queue")
+	var req ReplayDeadLettersRequest
+	// An empty body replays the whole queue
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.InvalidJsonErrorResponse(w)
+		return
+	}
+
+	service := service.NewDeadLetterService()
+	replayed, err := service.Replay(ctx, queueName, req.Ids)
+	if err != nil {
+		writeDeadLetterError(w, r, err)
+		return
+	}
+	logger.InfoCtx(ctx, "Replayed %d dead letters of %s queue", replayed, queueName)
+	response.SetDeadLettersReplayedResponse(w, ctx, replayed)
+}
+
+func (h *Handler) PurgeDeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queueName := chi.URLParam(r, "queue")
+
+	service := service.NewDeadLetterService()
+	purged, err := service.Purge(ctx, queueName)
+	if err != nil {
+		writeDeadLetterError(w, r, err)
+		return
+	}
+	logger.InfoCtx(ctx, "Purged %d dead letters of %s queue", purged, queueName)
+	response.SetDeadLettersPurgedResponse(w, ctx, purged)
+}
+
+func writeDeadLetterError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, service.ErrUnknownTaskQueue) {
+		response.NotFoundErrorResponse(w)
+		return
+	}
+	logger.ErrorCtx(r.Context(), "Dead letter operation failed: %v", err)
+	response.InternalServerError(w)
+}
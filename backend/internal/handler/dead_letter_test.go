@@ -0,0 +1,59 @@
+package handler_test
+
+import (
+	"backend/internal/handler"
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+func TestDeadLetterHandlers_UnknownQueue(t *testing.T) {
+	h := handler.NewHandler()
+
+	tests := []struct {
+		name    string
+		method  string
+		url     string
+		handler http.HandlerFunc
+	}{
+		{name: "list", method: http.MethodGet, url: "/admin/queues/unknown/dlq", handler: h.ListDeadLettersHandler},
+		{name: "replay", method: http.MethodPost, url: "/admin/queues/unknown/dlq/replay", handler: h.ReplayDeadLettersHandler},
+		{name: "purge", method: http.MethodDelete, url: "/admin/queues/unknown/dlq", handler: h.PurgeDeadLettersHandler},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, rr := NewTestRequest(tt.method, tt.url, nil, TestDeps{UserID: 1, URLParams: map[string]string{"queue": "unknown"}})
+
+			tt.handler(rr, req)
+
+			if rr.Code != http.StatusNotFound {
+				t.Errorf("expected status 404, got %d", rr.Code)
+			}
+		})
+	}
+}
+
+func TestListDeadLettersHandler_InvalidLimit(t *testing.T) {
+	h := handler.NewHandler()
+
+	req, rr := NewTestRequest(http.MethodGet, "/admin/queues/email/dlq?limit=abc", nil, TestDeps{UserID: 1, URLParams: map[string]string{"queue": "email"}})
+
+	h.ListDeadLettersHandler(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
+
+func TestReplayDeadLettersHandler_InvalidJSON(t *testing.T) {
+	h := handler.NewHandler()
+
+	req, rr := NewTestRequest(http.MethodPost, "/admin/queues/email/dlq/replay", bytes.NewBufferString("invalid json"), TestDeps{UserID: 1, URLParams: map[string]string{"queue": "email"}})
+
+	h.ReplayDeadLettersHandler(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"backend/internal/contexthelper"
+	"backend/internal/featureflag"
+	"backend/internal/repository"
+	"backend/internal/response"
+	"backend/pkg/logger"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type SetFeatureFlagRequest struct {
+	Enabled        *bool `json:"enabled"`
+	RolloutPercent *int  `json:"rollout_percent"`
+}
+
+type SetFeatureFlagOverrideRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+func (h *Handler) ListFeatureFlagsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	flags := contexthelper.GetFeatureFlags(ctx)
+	response.SetFeatureFlagsResponse(w, ctx, flags.Flags())
+}
+
+func (h *Handler) SetFeatureFlagHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name := chi.URLParam(r, "name")
+	var req SetFeatureFlagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.InvalidJsonErrorResponse(w)
+		return
+	}
+	if req.Enabled == nil {
+		response.InvalidInputValueErrorResponse(w, "enabled", "enabled field is required")
+		return
+	}
+	rolloutPercent := 100
+	if req.RolloutPercent != nil {
+		rolloutPercent = *req.RolloutPercent
+	}
+	if rolloutPercent < 0 || rolloutPercent > 100 {
+		response.InvalidInputValueErrorResponse(w, "rollout_percent", "rollout_percent must be between 0 and 100")
+		return
+	}
+
+	flags := contexthelper.GetFeatureFlags(ctx)
+	if err := flags.Set(ctx, name, *req.Enabled, rolloutPercent); err != nil {
+		writeFeatureFlagError(w, r, err)
+		return
+	}
+	logger.InfoCtx(ctx, "Feature flag %s set to enabled=%v, rollout=%d%%", name, *req.Enabled, rolloutPercent)
+	response.FeatureFlagUpdatedResponse(w, ctx)
+}
+
+func (h *Handler) SetFeatureFlagOverrideHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name := chi.URLParam(r, "name")
+	userId, ok := featureFlagUserId(w, r)
+	if !ok {
+		return
+	}
+	var req SetFeatureFlagOverrideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.InvalidJsonErrorResponse(w)
+		return
+	}
+	if req.Enabled == nil {
+		response.InvalidInputValueErrorResponse(w, "enabled", "enabled field is required")
+		return
+	}
+
+	if _, err := repository.NewUserRepository(contexthelper.GetDb(ctx)).GetById(ctx, userId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.NotFoundErrorResponse(w)
+			return
+		}
+		logger.ErrorCtx(ctx, "Failed to get user %d: %v", userId, err)
+		response.InternalServerError(w)
+		return
+	}
+
+	flags := contexthelper.GetFeatureFlags(ctx)
+	if err := flags.SetOverride(ctx, name, userId, *req.Enabled); err != nil {
+		writeFeatureFlagError(w, r, err)
+		return
+	}
+	logger.InfoCtx(ctx, "Feature flag %s overridden for user %d: enabled=%v", name, userId, *req.Enabled)
+	response.FeatureFlagUpdatedResponse(w, ctx)
+}
+
+func (h *Handler) DeleteFeatureFlagOverrideHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name := chi.URLParam(r, "name")
+	userId, ok := featureFlagUserId(w, r)
+	if !ok {
+		return
+	}
+
+	flags := contexthelper.GetFeatureFlags(ctx)
+	if err := flags.DeleteOverride(ctx, name, userId); err != nil {
+		writeFeatureFlagError(w, r, err)
+		return
+	}
+	logger.InfoCtx(ctx, "Feature flag %s override removed for user %d", name, userId)
+	response.FeatureFlagUpdatedResponse(w, ctx)
+}
+
+func featureFlagUserId(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	userId, err := strconv.ParseUint(chi.URLParam(r, "userId"), 10, 32)
+	if err != nil || userId == 0 {
+		response.InvalidInputValueErrorResponse(w, "userId", "userId must be a positive number")
+		return 0, false
+	}
+	return uint(userId), true
+}
+
+func writeFeatureFlagError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, featureflag.ErrUnknownFlag) {
+		response.FeatureFlagErrorNotFound(w)
+		return
+	}
+	logger.ErrorCtx(r.Context(), "Feature flag operation failed: %v", err)
+	response.InternalServerError(w)
+}
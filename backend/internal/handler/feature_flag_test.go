@@ -0,0 +1,210 @@
+package handler_test
+
+import (
+	"backend/config"
+	"backend/internal/featureflag"
+	"backend/internal/handler"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func withFeaturesDisabled(cfg *config.Config) {
+	cfg.Register.Enabled = false
+	cfg.ResetPassword.Enabled = false
+}
+
+func expectFeatureFlagRefresh(mock sqlmock.Sqlmock, name string, enabled bool, rolloutPercent int) {
+	mock.ExpectQuery("SELECT name, enabled, rollout_percent, updated_at FROM feature_flags").WillReturnRows(
+		sqlmock.NewRows([]string{"name", "enabled", "rollout_percent", "updated_at"}).AddRow(name, enabled, rolloutPercent, time.Now()),
+	)
+	mock.ExpectQuery("SELECT flag_name, user_id, enabled FROM feature_flag_overrides").WillReturnRows(
+		sqlmock.NewRows([]string{"flag_name", "user_id", "enabled"}),
+	)
+}
+
+func TestFeatureFlags_DisabledFeatures(t *testing.T) {
+	h := handler.NewHandler()
+	cfg := testConfig(withFeaturesDisabled)
+
+	tests := []struct {
+		name    string
+		url     string
+		body    string
+		handler http.HandlerFunc
+	}{
+		{name: "register", url: "/register", body: `{"username":"test","email":"test@example.com","password":"Test123!@#"}`, handler: h.RegisterHandler},
+		{name: "register resend", url: "/register/resend", body: `{"email":"test@example.com"}`, handler: h.RegisterResendHandler},
+		{name: "reset password", url: "/reset-password", body: `{"email":"test@example.com"}`, handler: h.ResetPasswordHandler},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, rr := NewTestRequest(http.MethodPost, tt.url, strings.NewReader(tt.body), TestDeps{Config: cfg})
+
+			tt.handler(rr, req)
+
+			if rr.Code != http.StatusForbidden {
+				t.Errorf("expected status 403, got %d", rr.Code)
+			}
+			if !strings.Contains(rr.Body.String(), "2301") {
+				t.Errorf("expected the feature disabled code, got %s", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestCfgHandler_FeatureFlagsFromStore(t *testing.T) {
+	flagDb, flagMock, _ := sqlmock.New()
+	defer flagDb.Close()
+	expectFeatureFlagRefresh(flagMock, featureflag.Register, false, 100)
+	flags := featureflag.NewStore(flagDb, map[string]bool{featureflag.Register: true, featureflag.ResetPassword: true})
+	if err := flags.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	mock.ExpectQuery("SELECT.*FROM languages").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "code", "name"}).AddRow(1, "en", "English"),
+	)
+
+	req, rr := NewTestRequest(http.MethodGet, "/cfg", nil, TestDeps{DB: db, FeatureFlags: flags})
+	handler.NewHandler().CfgHandler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var resp struct {
+		Data struct {
+			Features config.FeaturesConfig
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("error decoding response: %v", err)
+	}
+	if resp.Data.Features.Register || !resp.Data.Features.ResetPassword {
+		t.Errorf("expected register off and reset password on, got %+v", resp.Data.Features)
+	}
+}
+
+func TestCfgHandler_AnonymousFeatureFlagsIgnoreOverrides(t *testing.T) {
+	flagDb, flagMock, _ := sqlmock.New()
+	defer flagDb.Close()
+	flagMock.ExpectQuery("SELECT name, enabled, rollout_percent, updated_at FROM feature_flags").WillReturnRows(
+		sqlmock.NewRows([]string{"name", "enabled", "rollout_percent", "updated_at"}).AddRow(featureflag.Register, false, 100, time.Now()),
+	)
+	// POST /register checks the flag without a user, so the override does not let user 7 register
+	flagMock.ExpectQuery("SELECT flag_name, user_id, enabled FROM feature_flag_overrides").WillReturnRows(
+		sqlmock.NewRows([]string{"flag_name", "user_id", "enabled"}).AddRow(featureflag.Register, 7, true),
+	)
+	flags := featureflag.NewStore(flagDb, map[string]bool{featureflag.Register: true, featureflag.ResetPassword: true})
+	if err := flags.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	mock.ExpectQuery("SELECT.*FROM languages").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "code", "name"}).AddRow(1, "en", "English"),
+	)
+
+	req, rr := NewTestRequest(http.MethodGet, "/cfg", nil, TestDeps{DB: db, UserID: 7, FeatureFlags: flags})
+	handler.NewHandler().CfgHandler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var resp struct {
+		Data struct {
+			Features config.FeaturesConfig
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("error decoding response: %v", err)
+	}
+	if resp.Data.Features.Register {
+		t.Errorf("expected register off for a user with an override, got %+v", resp.Data.Features)
+	}
+}
+
+func TestSetFeatureFlagHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		flag       string
+		body       string
+		mock       func(sqlmock.Sqlmock)
+		wantStatus int
+	}{
+		{
+			name: "success",
+			flag: featureflag.Register,
+			body: `{"enabled":false}`,
+			mock: func(m sqlmock.Sqlmock) {
+				m.ExpectExec("INSERT INTO feature_flags").WithArgs(featureflag.Register, false, 100).WillReturnResult(sqlmock.NewResult(0, 1))
+				expectFeatureFlagRefresh(m, featureflag.Register, false, 100)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{name: "unknown flag", flag: "unknown", body: `{"enabled":true}`, wantStatus: http.StatusNotFound},
+		{name: "missing enabled", flag: featureflag.Register, body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid rollout", flag: featureflag.Register, body: `{"enabled":true,"rollout_percent":101}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid json", flag: featureflag.Register, body: `{`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+			if tt.mock != nil {
+				tt.mock(mock)
+			}
+			flags := featureflag.NewStore(db, map[string]bool{featureflag.Register: true})
+
+			req, rr := NewTestRequest(http.MethodPut, "/admin/feature_flags/"+tt.flag, bytes.NewBufferString(tt.body), TestDeps{DB: db, UserID: 1, FeatureFlags: flags, URLParams: map[string]string{"name": tt.flag}})
+			handler.NewHandler().SetFeatureFlagHandler(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d", tt.wantStatus, rr.Code)
+			}
+			if rr.Code == http.StatusOK && flags.Enabled(featureflag.Register, 0) {
+				t.Error("expected the flag to be applied at once")
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestSetFeatureFlagOverrideHandler_UserNotFound(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	mock.ExpectQuery("SELECT id, name, email, password, registered_at, confirmed_at").WithArgs(42).WillReturnError(sql.ErrNoRows)
+
+	req, rr := NewTestRequest(http.MethodPut, "/admin/feature_flags/register/users/42", bytes.NewBufferString(`{"enabled":true}`), TestDeps{DB: db, UserID: 1, URLParams: map[string]string{"name": featureflag.Register, "userId": "42"}})
+	handler.NewHandler().SetFeatureFlagOverrideHandler(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rr.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteFeatureFlagOverrideHandler_InvalidUserId(t *testing.T) {
+	req, rr := NewTestRequest(http.MethodDelete, "/admin/feature_flags/register/users/abc", nil, TestDeps{UserID: 1, URLParams: map[string]string{"name": featureflag.Register, "userId": "abc"}})
+	handler.NewHandler().DeleteFeatureFlagOverrideHandler(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"backend/internal/contexthelper"
+	"backend/internal/metrics"
 	"backend/internal/repository"
 	"backend/internal/response"
 	"backend/internal/service"
@@ -28,6 +29,7 @@ func (h *Handler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	logger.DebugCtx(ctx, "Login request: %v", req)
 	if req.Email == "" || req.Password == "" {
 		logger.ErrorCtx(ctx, "Invalid login request", "error", "email or password is empty")
+		metrics.Login(metrics.LoginPassword, false)
 		response.LoginErrorInvalidCredentials(w)
 		return
 	}
@@ -40,10 +42,12 @@ func (h *Handler) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	user, err := authService.Login(ctx, req.Email, req.Password)
 	if err != nil {
 		logger.ErrorCtx(ctx, "Login failed: %v", err)
+		metrics.Login(metrics.LoginPassword, false)
 		response.LoginErrorInvalidCredentials(w)
 		return
 	}
 	logger.InfoCtx(ctx, "User %d logged in successfully", user.Id)
+	metrics.Login(metrics.LoginPassword, true)
 	accessTokenData, ctx := contexthelper.GetAccessTokenData(ctx)
 	accessTokenData.SetCookies = true
 	accessTokenData.UserId = user.Id
@@ -2,6 +2,7 @@ package handler_test
 
 import (
 	"backend/internal/handler"
+	"backend/internal/metrics"
 	"bytes"
 	"database/sql"
 	"encoding/json"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -147,6 +149,8 @@ func TestLoginHandler_InvalidCredentials(t *testing.T) {
 
 	// Mock user not found
 	mock.ExpectQuery("SELECT.*FROM users").WillReturnError(sql.ErrNoRows)
+	failures := metrics.LoginsTotal.WithLabelValues(metrics.LoginPassword, metrics.ResultFailure)
+	failuresBefore := testutil.ToFloat64(failures)
 
 	reqBody := map[string]string{
 		"email":    "test@example.com",
@@ -165,6 +169,9 @@ func TestLoginHandler_InvalidCredentials(t *testing.T) {
 	if rr.Code != http.StatusUnauthorized {
 		t.Errorf("expected status 401, got %d", rr.Code)
 	}
+	if got := testutil.ToFloat64(failures) - failuresBefore; got != 1 {
+		t.Errorf("expected 1 failed login to be counted, got %v", got)
+	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("there were unfulfilled expectations: %s", err)
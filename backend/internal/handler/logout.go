@@ -23,7 +23,7 @@ func (h *Handler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
 	sessionRepo := repository.NewUserSessionsRepository(db)
 	sessionService := service.NewSessionService(sessionRepo, w)
 	token := cookie.GetRefreshToken(r)
-	logger.DebugCtx(ctx, "logout token: %s", token)
+	logger.DebugCtx(ctx, "logout token: %s", logger.Secret(token))
 	err := sessionService.Logout(ctx, token)
 	accessTokenData, ctx := contexthelper.GetAccessTokenData(ctx)
 	accessTokenData.SetCookies = false
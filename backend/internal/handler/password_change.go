@@ -2,15 +2,16 @@ package handler
 
 import (
 	"backend/internal/contexthelper"
+	"backend/internal/models"
 	"backend/internal/repository"
 	"backend/internal/response"
-	"backend/internal/service"
 	"backend/pkg/logger"
 	"backend/pkg/validation"
 	"encoding/json"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/pkg/errors"
 )
 
 type PasswordChangeRequest struct {
@@ -20,26 +21,13 @@ type PasswordChangeRequest struct {
 func (h *Handler) PasswordChangeHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	token := chi.URLParam(r, "token")
-	logger.DebugCtx(ctx, "ConfirmHandler called with token: %s", token)
+	logger.DebugCtx(ctx, "ConfirmHandler called with token: %s", logger.Secret(token))
 	if token == "" {
 		logger.WarnCtx(ctx, "Empty confirmation token provided")
 		response.NotFoundErrorResponse(w)
 		return
 	}
 
-	db := contexthelper.GetDb(ctx)
-	ctRepo := repository.NewConfirmationTokenRepository(db)
-	if ctRepo == nil {
-		response.InternalServerError(w)
-		return
-	}
-	ct, err := ctRepo.GetActiveNewToken(ctx, token)
-	if err != nil || ct.Id == 0 {
-		logger.DebugCtx(ctx, "No active confirmation token found", "token", token, "error", err)
-		response.NotFoundErrorResponse(w)
-		return
-	}
-
 	var req PasswordChangeRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		response.InvalidJsonErrorResponse(w)
@@ -52,17 +40,49 @@ func (h *Handler) PasswordChangeHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	uRepo := repository.NewUserRepository(db)
-	service := service.NewPasswordService(ctRepo, uRepo)
-	err = service.PasswordChange(ctx, ct.UserId, req.Password)
+	// Claiming the token, changing the password and consuming the token happen in one
+	// transaction, so the token is used once
+	db := contexthelper.GetDb(ctx)
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to begin transaction: %v", err)
+		response.InternalServerError(w)
+		return
+	}
+	defer tx.Rollback()
 
+	txCtRepo := repository.NewConfirmationTokenRepository(tx)
+	ct, err := txCtRepo.ClaimToken(ctx, token)
+	if errors.Is(err, repository.ErrConfirmationTokenConsumed) {
+		logger.InfoCtx(ctx, "Password change token already used")
+		response.SetConfirmAlreadyUsedResponse(w)
+		return
+	}
 	if err != nil {
+		logger.WarnCtx(ctx, "No active password change token found: %v", err)
+		response.NotFoundErrorResponse(w)
+		return
+	}
+	if ct.Type != models.ConfirmationTokenTypePasswordChange {
+		logger.WarnCtx(ctx, "Confirmation token of type %s used for password change", ct.Type)
+		response.NotFoundErrorResponse(w)
+		return
+	}
+	err = h.passwordChangeHandler(ctx, tx, ct, req.Password)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to change password: %v", err)
 		response.InternalServerError(w)
 		return
 	}
-	err = ctRepo.ConsumeToken(ctx, token)
+	err = txCtRepo.ConsumeToken(ctx, token)
 	if err != nil {
 		logger.ErrorCtx(ctx, "Failed to consume confirmation token: %v", err)
+		response.InternalServerError(w)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		logger.ErrorCtx(ctx, "Failed to commit password change: %v", err)
+		response.InternalServerError(w)
 		return
 	}
 
@@ -27,8 +27,9 @@ func TestPasswordChangeHandler_Success(t *testing.T) {
 	token := "test-token-123"
 	newPassword := "NewPassword123!@#"
 
-	// Mock confirmation token lookup
-	mock.ExpectQuery("SELECT.*FROM confirmation_tokens").WillReturnRows(
+	// Mock token claim in the password change transaction
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT.*FROM confirmation_tokens .*FOR UPDATE").WillReturnRows(
 		sqlmock.NewRows([]string{"id", "token", "user_id", "type", "payload", "status", "expires_at", "status_changed_at", "created_at"}).
 			AddRow(1, token, 1, "password_change", "{}", "NEW", time.Now().Add(1*time.Hour), time.Now(), time.Now()),
 	)
@@ -38,6 +39,7 @@ func TestPasswordChangeHandler_Success(t *testing.T) {
 
 	// Mock token consume
 	mock.ExpectExec("UPDATE confirmation_tokens").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 
 	// Create request
 	reqBody := map[string]string{
@@ -102,11 +104,7 @@ func TestPasswordChangeHandler_InvalidJSON(t *testing.T) {
 
 	token := "test-token-123"
 
-	// Mock confirmation token lookup (handler checks token before parsing JSON)
-	mock.ExpectQuery("SELECT.*FROM confirmation_tokens").WillReturnRows(
-		sqlmock.NewRows([]string{"id", "token", "user_id", "type", "payload", "status", "expires_at", "status_changed_at", "created_at"}).
-			AddRow(1, token, 1, "password_change", "{}", "NEW", time.Now().Add(1*time.Hour), time.Now(), time.Now()),
-	)
+	// The body is rejected before the token is claimed
 	req, rr := NewTestRequest(
 		http.MethodPost,
 		"/password-change/"+token,
@@ -144,12 +142,7 @@ func TestPasswordChangeHandler_InvalidPassword(t *testing.T) {
 
 	token := "test-token-123"
 
-	// Mock confirmation token lookup
-	mock.ExpectQuery("SELECT.*FROM confirmation_tokens").WillReturnRows(
-		sqlmock.NewRows([]string{"id", "token", "user_id", "type", "payload", "status", "expires_at", "status_changed_at", "created_at"}).
-			AddRow(1, token, 1, "password_change", "{}", "NEW", time.Now().Add(1*time.Hour), time.Now(), time.Now()),
-	)
-
+	// The password is rejected before the token is claimed
 	reqBody := map[string]string{
 		"password": "weak",
 	}
@@ -191,7 +184,9 @@ func TestPasswordChangeHandler_TokenNotFound(t *testing.T) {
 	token := "invalid-token"
 
 	// Mock confirmation token not found
-	mock.ExpectQuery("SELECT.*FROM confirmation_tokens").WillReturnError(sql.ErrNoRows)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT.*FROM confirmation_tokens .*FOR UPDATE").WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
 
 	reqBody := map[string]string{
 		"password": "NewPassword123!@#",
@@ -222,3 +217,97 @@ func TestPasswordChangeHandler_TokenNotFound(t *testing.T) {
 	}
 }
 
+
+func TestPasswordChangeHandler_TokenAlreadyUsed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	h := handler.NewHandler()
+
+	token := "test-token-123"
+	columns := []string{"id", "token", "user_id", "type", "payload", "status", "expires_at", "status_changed_at", "created_at"}
+
+	// A concurrent request consumed the token before this one could claim it
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT.*FROM confirmation_tokens .*FOR UPDATE").WillReturnRows(
+		sqlmock.NewRows(columns).AddRow(1, token, 1, "password_change", "{}", "CONSUMED", time.Now().Add(1*time.Hour), time.Now(), time.Now()),
+	)
+	mock.ExpectRollback()
+
+	body, _ := json.Marshal(map[string]string{"password": "NewPassword123!@#"})
+	req, rr := NewTestRequest(
+		http.MethodPost,
+		"/password-change/"+token,
+		bytes.NewBuffer(body),
+		TestDeps{DB: db},
+	)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("token", token)
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+
+	h.PasswordChangeHandler(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected status 409, got %d", rr.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPasswordChangeHandler_ClaimedToken(t *testing.T) {
+	columns := []string{"id", "token", "user_id", "type", "payload", "status", "expires_at", "status_changed_at", "created_at"}
+	tests := []struct {
+		name       string
+		tokenType  string
+		status     string
+		wantStatus int
+	}{
+		// The reset email is out, but its delivery was not recorded yet
+		{name: "processing token", tokenType: "password_change", status: "PROCESSING", wantStatus: http.StatusOK},
+		{name: "other token type", tokenType: "email_change", status: "NEW", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create sqlmock: %v", err)
+			}
+			defer db.Close()
+
+			token := "test-token-123"
+			mock.ExpectBegin()
+			mock.ExpectQuery("SELECT.*FROM confirmation_tokens .*FOR UPDATE").WillReturnRows(
+				sqlmock.NewRows(columns).AddRow(1, token, 1, tt.tokenType, "{}", tt.status, time.Now().Add(1*time.Hour), time.Now(), time.Now()),
+			)
+			if tt.wantStatus == http.StatusOK {
+				mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE confirmation_tokens").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			body, _ := json.Marshal(map[string]string{"password": "NewPassword123!@#"})
+			req, rr := NewTestRequest(
+				http.MethodPost,
+				"/password-change/"+token,
+				bytes.NewBuffer(body),
+				TestDeps{DB: db, URLParams: map[string]string{"token": token}},
+			)
+
+			handler.NewHandler().PasswordChangeHandler(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"backend/internal/apperrors"
 	"backend/internal/contexthelper"
+	"backend/internal/featureflag"
 	"backend/internal/repository"
 	"backend/internal/response"
 	"backend/internal/service"
@@ -27,13 +28,17 @@ func (h *Handler) RegisterHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	ctx := r.Context()
+	if !contexthelper.GetFeatureFlags(ctx).Enabled(featureflag.Register, 0) {
+		response.FeatureDisabledErrorResponse(w)
+		return
+	}
 
 	var req RegisterRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		response.InvalidJsonErrorResponse(w)
 		return
 	}
-	logger.InfoCtx(ctx, "RegisterHandler called with username: %s, email: %s, language: %s", req.Username, req.Email, req.Language)
+	logger.InfoCtx(ctx, "RegisterHandler called with username: %s, email: %s, language: %s", req.Username, logger.Email(req.Email), req.Language)
 
 	t := reflect.TypeOf(req)
 	v := reflect.ValueOf(req)
@@ -58,7 +63,7 @@ func (h *Handler) RegisterHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		logger.ErrorCtx(ctx, "Failed to register user: %v", err)
 		if apperrors.IsRegisterUserNameOrEmailTakenError(err) {
-			logger.InfoCtx(ctx, "Username or email already taken: %s, %s", req.Username, req.Email)
+			logger.InfoCtx(ctx, "Username or email already taken: %s, %s", req.Username, logger.Email(req.Email))
 			response.RegisterErrorUserNameOrEmailTaken(w)
 		} else if apperrors.IsAppInvalidInputError(err) {
 			logger.InfoCtx(ctx, "Invalid input: %v", err)
@@ -73,6 +78,6 @@ func (h *Handler) RegisterHandler(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	logger.InfoCtx(ctx, "User %s (%s) registered successfully", req.Username, req.Email)
+	logger.InfoCtx(ctx, "User %s (%s) registered successfully", req.Username, logger.Email(req.Email))
 	response.SetRegisterSuccessResponse(w, ctx)
 }
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"backend/internal/apperrors"
+	"backend/internal/contexthelper"
+	"backend/internal/models"
+	"backend/internal/report"
+	"backend/internal/repository"
+	"backend/internal/response"
+	"backend/internal/service"
+	"backend/pkg/logger"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	reportDateFormat        = "2006-01-02"
+	defaultReportPeriodDays = 30
+)
+
+type ReportRequest struct {
+	Type   string `json:"type"`
+	Format string `json:"format"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+var reportContentTypes = map[string]string{
+	models.ReportFormatCsv:  "text/csv",
+	models.ReportFormatXlsx: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+func (h *Handler) CreateReportHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId, ok := contexthelper.GetUserId(ctx)
+	if !ok {
+		response.UnauthorizedErrorResponse(w, "User not found")
+		return
+	}
+	var req ReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.InvalidJsonErrorResponse(w)
+		return
+	}
+	if _, ok := report.Get(req.Type); !ok {
+		response.InvalidInputValueErrorResponse(w, "type", "type must be one of: "+strings.Join(report.Names(), ", "))
+		return
+	}
+	if req.Format == "" {
+		req.Format = models.ReportFormatCsv
+	}
+	if !report.IsSupportedFormat(req.Format) {
+		response.InvalidInputValueErrorResponse(w, "format", "format must be csv or xlsx")
+		return
+	}
+	params, field, err := parseReportPeriod(req.From, req.To)
+	if err != nil {
+		response.InvalidInputValueErrorResponse(w, field, err.Error())
+		return
+	}
+
+	db := contexthelper.GetDb(ctx)
+	service := service.NewReportService(repository.NewReportRepository(db), repository.NewUserRepository(db))
+	reportId, err := service.RequestReport(ctx, userId, req.Type, req.Format, params)
+	if err != nil {
+		if apperrors.IsReportForbiddenError(err) {
+			logger.InfoCtx(ctx, "Report request rejected for user %d", userId)
+			response.ReportErrorForbidden(w)
+		} else {
+			logger.ErrorCtx(ctx, "Failed to request report: %v", err)
+			response.InternalServerError(w)
+		}
+		return
+	}
+	logger.InfoCtx(ctx, "Report %d (%s) requested by user %d", reportId, req.Type, userId)
+	response.ReportQueuedResponse(w, ctx, reportId)
+}
+
+func (h *Handler) GetReportHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId, reportId, ok := reportRequestIds(w, r)
+	if !ok {
+		return
+	}
+	db := contexthelper.GetDb(ctx)
+	service := service.NewReportService(repository.NewReportRepository(db), repository.NewUserRepository(db))
+	rep, err := service.GetReport(ctx, userId, reportId)
+	if err != nil {
+		writeReportError(w, r, err)
+		return
+	}
+	response.SetReportSuccessResponse(w, ctx, rep)
+}
+
+func (h *Handler) ReportFileHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId, reportId, ok := reportRequestIds(w, r)
+	if !ok {
+		return
+	}
+	db := contexthelper.GetDb(ctx)
+	service := service.NewReportService(repository.NewReportRepository(db), repository.NewUserRepository(db))
+	rep, err := service.GetReportFile(ctx, userId, reportId)
+	if err != nil {
+		writeReportError(w, r, err)
+		return
+	}
+	fileName := fmt.Sprintf("report_%d_%s.%s", rep.Id, rep.Type, rep.Format)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	w.Header().Set("Content-Type", reportContentTypes[rep.Format])
+	http.ServeFile(w, r, rep.OutputPath)
+}
+
+func reportRequestIds(w http.ResponseWriter, r *http.Request) (uint, uint, bool) {
+	userId, ok := contexthelper.GetUserId(r.Context())
+	if !ok {
+		response.UnauthorizedErrorResponse(w, "User not found")
+		return 0, 0, false
+	}
+	reportId, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil || reportId == 0 {
+		response.ReportErrorNotFound(w)
+		return 0, 0, false
+	}
+	return userId, uint(reportId), true
+}
+
+func writeReportError(w http.ResponseWriter, r *http.Request, err error) {
+	if apperrors.IsReportNotFoundError(err) {
+		response.ReportErrorNotFound(w)
+	} else if apperrors.IsReportNotReadyError(err) {
+		response.ReportErrorNotReady(w)
+	} else {
+		logger.ErrorCtx(r.Context(), "Failed to get report: %v", err)
+		response.InternalServerError(w)
+	}
+}
+
+// parseReportPeriod converts the inclusive date range of the request into report params.
+// Without dates the report covers the last defaultReportPeriodDays days.
+func parseReportPeriod(from, to string) (report.Params, string, error) {
+	end := time.Now().Truncate(24 * time.Hour)
+	if to != "" {
+		t, err := time.Parse(reportDateFormat, to)
+		if err != nil {
+			return report.Params{}, "to", fmt.Errorf("to must be a date in format YYYY-MM-DD")
+		}
+		end = t
+	}
+	start := end.AddDate(0, 0, -defaultReportPeriodDays)
+	if from != "" {
+		t, err := time.Parse(reportDateFormat, from)
+		if err != nil {
+			return report.Params{}, "from", fmt.Errorf("from must be a date in format YYYY-MM-DD")
+		}
+		start = t
+	}
+	if start.After(end) {
+		return report.Params{}, "from", fmt.Errorf("from must not be after to")
+	}
+	return report.Params{From: start, To: end.AddDate(0, 0, 1)}, "", nil
+}
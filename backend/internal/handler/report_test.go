@@ -0,0 +1,219 @@
+package handler_test
+
+import (
+	"backend/config"
+	"backend/internal/handler"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var reportColumns = []string{"id", "user_id", "type", "format", "params", "status", "output_path", "error", "created_at", "started_at", "finished_at"}
+
+func withReports(cfg *config.Config) {
+	cfg.Reports.AllowedEmails = []string{"analyst@example.com"}
+}
+
+func TestCreateReportHandler_Unauthorized(t *testing.T) {
+	h := handler.NewHandler()
+
+	body, _ := json.Marshal(map[string]string{"type": "user_registrations"})
+	req, rr := NewTestRequest(http.MethodPost, "/reports", bytes.NewBuffer(body), TestDeps{})
+
+	h.CreateReportHandler(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d", rr.Code)
+	}
+}
+
+func TestCreateReportHandler_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name string
+		body map[string]string
+	}{
+		{name: "unknown type", body: map[string]string{"type": "unknown"}},
+		{name: "unsupported format", body: map[string]string{"type": "user_registrations", "format": "pdf"}},
+		{name: "invalid date", body: map[string]string{"type": "user_registrations", "from": "01.01.2026"}},
+		{name: "from after to", body: map[string]string{"type": "user_sessions", "from": "2026-02-01", "to": "2026-01-01"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := handler.NewHandler()
+
+			body, _ := json.Marshal(tt.body)
+			req, rr := NewTestRequest(http.MethodPost, "/reports", bytes.NewBuffer(body), TestDeps{UserID: 1, Config: testConfig(withReports)})
+
+			h.CreateReportHandler(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("expected status 400, got %d", rr.Code)
+			}
+		})
+	}
+}
+
+func TestCreateReportHandler_Forbidden(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	h := handler.NewHandler()
+
+	mock.ExpectQuery("SELECT.*FROM users WHERE id").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name", "email", "password", "registered_at", "confirmed_at"}).
+			AddRow(1, "testuser", "test@example.com", "hash", time.Now(), time.Now()),
+	)
+
+	body, _ := json.Marshal(map[string]string{"type": "user_registrations", "format": "xlsx"})
+	req, rr := NewTestRequest(http.MethodPost, "/reports", bytes.NewBuffer(body), TestDeps{DB: db, UserID: 1, Config: testConfig(withReports)})
+
+	h.CreateReportHandler(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("expected status 403, got %d", rr.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetReportHandler_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	h := handler.NewHandler()
+
+	mock.ExpectQuery("SELECT.*FROM reports WHERE id = \\? AND user_id = \\?").
+		WithArgs(5, 1).
+		WillReturnRows(sqlmock.NewRows(reportColumns).
+			AddRow(5, 1, "user_registrations", "csv", "{}", "running", nil, nil, time.Now(), time.Now(), nil))
+
+	req, rr := NewTestRequest(http.MethodGet, "/reports/5", nil, TestDeps{DB: db, UserID: 1, Config: testConfig(withReports), URLParams: map[string]string{"id": "5"}})
+
+	h.GetReportHandler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var resp struct {
+		Data struct {
+			Status string `json:"status"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Data.Status != "running" {
+		t.Errorf("expected status running, got %q", resp.Data.Status)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetReportHandler_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	h := handler.NewHandler()
+
+	mock.ExpectQuery("SELECT.*FROM reports WHERE id = \\? AND user_id = \\?").WillReturnError(sql.ErrNoRows)
+
+	req, rr := NewTestRequest(http.MethodGet, "/reports/5", nil, TestDeps{DB: db, UserID: 1, Config: testConfig(withReports), URLParams: map[string]string{"id": "5"}})
+
+	h.GetReportHandler(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rr.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetReportHandler_InvalidId(t *testing.T) {
+	h := handler.NewHandler()
+
+	req, rr := NewTestRequest(http.MethodGet, "/reports/abc", nil, TestDeps{UserID: 1, Config: testConfig(withReports), URLParams: map[string]string{"id": "abc"}})
+
+	h.GetReportHandler(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rr.Code)
+	}
+}
+
+func TestReportFileHandler_NotReady(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	h := handler.NewHandler()
+
+	mock.ExpectQuery("SELECT.*FROM reports WHERE id = \\? AND user_id = \\?").
+		WillReturnRows(sqlmock.NewRows(reportColumns).
+			AddRow(5, 1, "user_registrations", "csv", "{}", "queued", nil, nil, time.Now(), nil, nil))
+
+	req, rr := NewTestRequest(http.MethodGet, "/reports/5/file", nil, TestDeps{DB: db, UserID: 1, Config: testConfig(withReports), URLParams: map[string]string{"id": "5"}})
+
+	h.ReportFileHandler(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Errorf("expected status 409, got %d", rr.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestReportFileHandler_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	path := filepath.Join(t.TempDir(), "report_1_5.csv")
+	if err := os.WriteFile(path, []byte("id,name\n"), 0640); err != nil {
+		t.Fatalf("failed to create report file: %v", err)
+	}
+
+	h := handler.NewHandler()
+
+	mock.ExpectQuery("SELECT.*FROM reports WHERE id = \\? AND user_id = \\?").
+		WillReturnRows(sqlmock.NewRows(reportColumns).
+			AddRow(5, 1, "user_registrations", "csv", "{}", "done", path, nil, time.Now(), time.Now(), time.Now()))
+
+	req, rr := NewTestRequest(http.MethodGet, "/reports/5/file", nil, TestDeps{DB: db, UserID: 1, Config: testConfig(withReports), URLParams: map[string]string{"id": "5"}})
+
+	h.ReportFileHandler(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if rr.Body.String() != "id,name\n" {
+		t.Errorf("unexpected body %q", rr.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
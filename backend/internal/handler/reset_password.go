@@ -2,6 +2,7 @@ package handler
 
 import (
 	"backend/internal/contexthelper"
+	"backend/internal/featureflag"
 	"backend/internal/repository"
 	"backend/internal/response"
 	"backend/internal/service"
@@ -18,6 +19,10 @@ type ResetPasswordRequest struct {
 
 func (h *Handler) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	if !contexthelper.GetFeatureFlags(ctx).Enabled(featureflag.ResetPassword, 0) {
+		response.FeatureDisabledErrorResponse(w)
+		return
+	}
 	var req ResetPasswordRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		response.InvalidJsonErrorResponse(w)
@@ -9,7 +9,7 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
-	amqp "github.com/rabbitmq/amqp091-go"
+	"backend/pkg/rabbitmq"
 )
 
 // TestResetPasswordHandler_Success is skipped for unit tests because it requires RabbitMQ connection
@@ -123,7 +123,7 @@ func TestResetPasswordHandler_UserNotFound(t *testing.T) {
 	}
 	defer db.Close()
 
-	rabbitConn := &amqp.Connection{}
+	rabbitConn := &rabbitmq.Connection{}
 	h := handler.NewHandler()
 
 	// Mock user not found
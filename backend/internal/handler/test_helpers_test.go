@@ -4,13 +4,17 @@ import (
 	"backend/config"
 	"backend/internal/contexthelper"
 	"backend/internal/cookie"
+	"backend/internal/featureflag"
+	"context"
 	"database/sql"
 	"fmt"
 	"io"
 	"math/rand"
 	"net/http"
 	"net/http/httptest"
-	amqp "github.com/rabbitmq/amqp091-go"
+	"backend/pkg/rabbitmq"
+
+	"github.com/go-chi/chi/v5"
 )
 
 type TestDeps struct {
@@ -19,7 +23,10 @@ type TestDeps struct {
 	UserID    uint
 	AccessTokenData *contexthelper.AccessTokenData
 	RequestID string
-	RabbitConn *amqp.Connection
+	RabbitConn *rabbitmq.Connection
+	FeatureFlags *featureflag.Store
+	// URLParams are the chi route parameters of the request, e.g. {"id": "5"}
+	URLParams map[string]string
 }
 
 func NewTestRequest(
@@ -45,6 +52,13 @@ func NewTestRequest(
 
 	ctx = contexthelper.SetConfig(ctx, cfg)
 
+	// Without a store the flags keep their values from the config
+	flags := deps.FeatureFlags
+	if flags == nil {
+		flags = featureflag.NewStore(deps.DB, featureflag.Defaults(cfg))
+	}
+	ctx = contexthelper.SetFeatureFlags(ctx, flags)
+
 	if deps.UserID != 0 {
 		ctx = contexthelper.SetUserId(ctx, deps.UserID)
 	}
@@ -60,6 +74,13 @@ func NewTestRequest(
 	if deps.AccessTokenData != nil {
 		ctx = contexthelper.SetAccessTokenData(ctx, deps.AccessTokenData)
 	}
+	if deps.URLParams != nil {
+		rctx := chi.NewRouteContext()
+		for key, value := range deps.URLParams {
+			rctx.URLParams.Add(key, value)
+		}
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+	}
 	req = req.WithContext(ctx)
 
 	rr := httptest.NewRecorder()
@@ -77,8 +98,16 @@ func IsAccessCookieSet(resp *http.Response) bool {
 	return false
 }
 
-func testConfig() *config.Config {
-	return &config.Config{
-		AppEnv: "test",
+// testConfig returns the config of test requests changed by the given mutators, e.g.
+// testConfig(withAccountDeletion).
+func testConfig(mutators ...func(cfg *config.Config)) *config.Config {
+	cfg := &config.Config{
+		AppEnv:        "test",
+		Register:      config.RegisterConfig{Enabled: true},
+		ResetPassword: config.ResetPasswordConfig{Enabled: true},
+	}
+	for _, mutate := range mutators {
+		mutate(cfg)
 	}
+	return cfg
 }
@@ -6,16 +6,15 @@ import (
 	"fmt"
 
 	"backend/internal/database"
-	"backend/internal/queue"
 	"backend/pkg/logger"
 
-	amqp "github.com/rabbitmq/amqp091-go"
+	"backend/pkg/rabbitmq"
 )
 
-func ConnectServicesWithRetry(ctx context.Context, dbDSN string, rabbitURL string) (*sql.DB, *amqp.Connection, error) {
+func ConnectServicesWithRetry(ctx context.Context, dbDSN string, rabbitURL string) (*sql.DB, *rabbitmq.Connection, error) {
 	dbCh := make(chan error, 1)
 	rabbitCh := make(chan struct {
-		conn *amqp.Connection
+		conn *rabbitmq.Connection
 		err  error
 	}, 1)
 
@@ -32,15 +31,15 @@ func ConnectServicesWithRetry(ctx context.Context, dbDSN string, rabbitURL strin
 
 	// RabbitMQ goroutine
 	go func() {
-		conn, err := queue.ConnectWithRetry(ctx, rabbitURL)
+		conn, err := rabbitmq.Dial(ctx, rabbitURL)
 		rabbitCh <- struct {
-			conn *amqp.Connection
+			conn *rabbitmq.Connection
 			err  error
 		}{conn, err}
 	}()
 
 	var dbErr error
-	var rabbitConn *amqp.Connection
+	var rabbitConn *rabbitmq.Connection
 	var rabbitErr error
 
 	for i := 0; i < 2; i++ {
@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"backend/pkg/logger"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Results of the queue, email and login metrics.
+const (
+	ResultSuccess   = "success"
+	ResultFailure   = "failure"
+	ResultDuplicate = "duplicate"
+	ResultInvalid   = "invalid"
+	ResultAborted   = "aborted"
+)
+
+// Login methods of LoginsTotal.
+const (
+	LoginPassword     = "password"
+	LoginRefreshToken = "refresh_token"
+)
+
+// routeUnmatched is the route of requests that did not match a route, so unknown paths
+// do not create new series.
+const routeUnmatched = "unmatched"
+
+var (
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Duration of HTTP requests by chi route pattern and status; _count is the number of requests.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	QueuePublishedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "queue_published_total",
+		Help: "Tasks published to a task queue by result.",
+	}, []string{"queue", "result"})
+
+	QueueConsumedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "queue_consumed_total",
+		Help: "Deliveries of a task queue by result (success, failure, duplicate, invalid, aborted).",
+	}, []string{"queue", "result"})
+
+	QueueRetriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "queue_retries_total",
+		Help: "Failed tasks republished to a retry queue.",
+	}, []string{"queue"})
+
+	QueueDeadLetteredTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "queue_dead_lettered_total",
+		Help: "Failed tasks moved to the dead letter queue after the last retry.",
+	}, []string{"queue"})
+
+	EmailSendDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "email_send_duration_seconds",
+		Help:    "Duration of SMTP sends by result.",
+		Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"result"})
+
+	LoginsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_logins_total",
+		Help: "Logins by method (password, refresh_token) and result.",
+	}, []string{"method", "result"})
+)
+
+// ObserveHTTPRequest records a handled request. route is the chi route pattern, empty
+// when no route matched.
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	if route == "" {
+		route = routeUnmatched
+	}
+	HTTPRequestDuration.WithLabelValues(method, route, fmt.Sprint(status)).Observe(duration.Seconds())
+}
+
+// Published records a task published to a task queue.
+func Published(queue string, err error) {
+	QueuePublishedTotal.WithLabelValues(queue, result(err)).Inc()
+}
+
+// ObserveEmailSend records an SMTP send started at start.
+func ObserveEmailSend(start time.Time, err error) {
+	EmailSendDuration.WithLabelValues(result(err)).Observe(time.Since(start).Seconds())
+}
+
+// Login records a login attempt.
+func Login(method string, success bool) {
+	if success {
+		LoginsTotal.WithLabelValues(method, ResultSuccess).Inc()
+		return
+	}
+	LoginsTotal.WithLabelValues(method, ResultFailure).Inc()
+}
+
+func result(err error) string {
+	if err != nil {
+		return ResultFailure
+	}
+	return ResultSuccess
+}
+
+// RegisterDB exports the connection pool statistics of db (sql.DB.Stats) labelled with name.
+func RegisterDB(db *sql.DB, name string) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Start serves the /metrics endpoint on its own port, so the metrics are not exposed
+// through the public proxy. The caller closes the returned server on shutdown.
+func Start(host string, port int) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	srv := &http.Server{
+		Addr:              fmt.Sprintf("%s:%d", host, port),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		logger.Info("Metrics server listening on %s", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Metrics server failed: %v", err)
+		}
+	}()
+	return srv
+}
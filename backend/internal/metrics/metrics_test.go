@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveHTTPRequest_UnmatchedRoute(t *testing.T) {
+	before := testutil.CollectAndCount(HTTPRequestDuration)
+
+	ObserveHTTPRequest(http.MethodGet, "", http.StatusNotFound, 5*time.Millisecond)
+	ObserveHTTPRequest(http.MethodGet, "", http.StatusNotFound, 5*time.Millisecond)
+	ObserveHTTPRequest(http.MethodGet, "/reports/{id}", http.StatusOK, 5*time.Millisecond)
+
+	if got := testutil.CollectAndCount(HTTPRequestDuration) - before; got != 2 {
+		t.Errorf("expected 2 new series, got %d", got)
+	}
+}
+
+func TestPublished(t *testing.T) {
+	Published("email", nil)
+	Published("email", errors.New("connection closed"))
+	Published("email", errors.New("connection closed"))
+
+	if got := testutil.ToFloat64(QueuePublishedTotal.WithLabelValues("email", ResultSuccess)); got != 1 {
+		t.Errorf("expected 1 published task, got %v", got)
+	}
+	if got := testutil.ToFloat64(QueuePublishedTotal.WithLabelValues("email", ResultFailure)); got != 2 {
+		t.Errorf("expected 2 failed publishes, got %v", got)
+	}
+}
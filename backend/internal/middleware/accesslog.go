@@ -1,18 +1,51 @@
 package middleware
 
 import (
+	"backend/internal/contexthelper"
+	"backend/internal/metrics"
 	"backend/pkg/logger"
+	"log/slog"
 	"net/http"
 	"time"
+
+	"github.com/go-chi/chi/v5"
 )
 
+// AccessLog logs every request once it is handled, with its route, status, duration and
+// the authenticated user, and records it in the HTTP metrics.
 func AccessLog(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		startTime := time.Now()
+		// The authentication middlewares fill in the same data, so the user is known afterwards
+		accessTokenData := &contexthelper.AccessTokenData{}
+		ctx := contexthelper.SetAccessTokenData(r.Context(), accessTokenData)
+		sw := &statusResponseWriter{
+			ResponseWriter: w,
+			status:         http.StatusOK,
+		}
 		frontendBase := r.Header.Get("X-Frontend-Base-URL")
-		logger.InfoCtx(r.Context(), r.Method+" "+frontendBase+r.URL.Path)
-		next.ServeHTTP(w, r)
+		logger.DebugCtx(ctx, "%s %s%s", r.Method, frontendBase, r.URL.Path)
+
+		next.ServeHTTP(sw, r.WithContext(ctx))
+
 		duration := time.Since(startTime)
-		logger.InfoCtx(r.Context(), r.Method+" "+frontendBase+r.URL.Path+" :: Executed in %v", duration)
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+		}
+		route := ""
+		if routeCtx := chi.RouteContext(ctx); routeCtx != nil {
+			route = routeCtx.RoutePattern()
+			attrs = append(attrs, slog.String("route", route))
+		}
+		metrics.ObserveHTTPRequest(r.Method, route, sw.status, duration)
+		attrs = append(attrs, slog.Int("status", sw.status), slog.Duration("duration", duration))
+		if frontendBase != "" {
+			attrs = append(attrs, slog.String("frontend_base_url", frontendBase))
+		}
+		if accessTokenData.UserId != 0 {
+			attrs = append(attrs, slog.Any("user_id", accessTokenData.UserId))
+		}
+		logger.Log(ctx, slog.LevelInfo, "Request handled", attrs...)
 	})
 }
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"backend/internal/contexthelper"
+	"backend/internal/repository"
+	"backend/internal/response"
+	"backend/pkg/logger"
+	"net/http"
+)
+
+// AdminOnly allows the request only for users listed in the admin configuration.
+// It has to run after AuthOnly.
+func AdminOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userId, _ := contexthelper.GetUserId(ctx)
+		user, err := repository.NewUserRepository(contexthelper.GetDb(ctx)).GetById(ctx, userId)
+		if err != nil {
+			logger.WarnCtx(ctx, "Failed to load user %d for admin check: %v", userId, err)
+			response.ForbiddenErrorResponse(w)
+			return
+		}
+		if !contexthelper.GetConfig(ctx).Admin.IsAdmin(user.Email) {
+			logger.WarnCtx(ctx, "User %d is not an admin", userId)
+			response.ForbiddenErrorResponse(w)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"backend/internal/contexthelper"
+	"backend/internal/featureflag"
+	"net/http"
+)
+
+func FeatureFlags(flags *featureflag.Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := contexthelper.SetFeatureFlags(r.Context(), flags)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
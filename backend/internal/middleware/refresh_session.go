@@ -3,6 +3,7 @@ package middleware
 import (
 	"backend/internal/contexthelper"
 	"backend/internal/cookie"
+	"backend/internal/metrics"
 	"backend/internal/repository"
 	"backend/internal/service"
 	"backend/pkg/logger"
@@ -19,7 +20,7 @@ func RefreshSession(next http.Handler) http.Handler {
 		}
 		accessTokenData, ctx := contexthelper.GetAccessTokenData(ctx)
 		refreshToken := cookie.GetRefreshToken(r)
-		logger.DebugCtx(ctx, "middleware refreshToken: %s", refreshToken)
+		logger.DebugCtx(ctx, "middleware refreshToken: %s", logger.Secret(refreshToken))
 		accessTokenData.RefreshToken = refreshToken
 		userId, ok := contexthelper.GetUserId(ctx)
 		logger.DebugCtx(ctx, "middleware accesTokenData: %v, userid: %d", accessTokenData, userId)
@@ -29,8 +30,9 @@ func RefreshSession(next http.Handler) http.Handler {
 			sessionRepo := repository.NewUserSessionsRepository(db)
 			sessionService := service.NewSessionService(sessionRepo, w)
 			userId, err = sessionService.Login(ctx, refreshToken)
+			metrics.Login(metrics.LoginRefreshToken, err == nil)
 			if err != nil {
-				logger.ErrorCtx(ctx, "Login user by refresh token %v failed: %v", refreshToken, err)
+				logger.ErrorCtx(ctx, "Login user by refresh token %v failed: %v", logger.Secret(refreshToken), err)
 				sessionService.Logout(ctx, refreshToken)
 				refreshToken = ""
 			}
@@ -5,16 +5,22 @@ import (
 
 	"backend/internal/contexthelper"
 	"backend/pkg/uuidstr"
+	"backend/pkg/validation"
 )
 
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID keeps a valid X-Request-ID sent by the client or the proxy, so one user action
+// can be followed across services, and generates a new one otherwise.
 func RequestID(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		requestID := uuidstr.GetUniqBase36(32)
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validation.IsRequestIdValid(requestID) {
+			requestID = uuidstr.GetUniqBase36(32)
+		}
 		// dodaj do kontekstu
 		ctx := contexthelper.SetRequestID(r.Context(), requestID)
-		// opcjonalnie: dodaj do nagłówków odpowiedzi
-		w.Header().Set("X-Request-ID", requestID)
+		w.Header().Set(RequestIDHeader, requestID)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
-
@@ -0,0 +1,53 @@
+package middleware_test
+
+import (
+	"backend/internal/contexthelper"
+	"backend/internal/middleware"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	var ctxRequestID string
+	srv := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxRequestID = contexthelper.GetRequestID(r.Context())
+	}))
+
+	cases := []struct {
+		name    string
+		inbound string
+		keep    bool
+	}{
+		{"valid inbound id is kept", "3f2a9c1e-7b4d-4e0a-9d8f-1c2b3a4d5e6f", true},
+		{"missing id is generated", "", false},
+		{"invalid id is replaced", "bad id\nwith newline", false},
+		{"too long id is replaced", strings.Repeat("a", 65), false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctxRequestID = ""
+			req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+			if tc.inbound != "" {
+				req.Header.Set(middleware.RequestIDHeader, tc.inbound)
+			}
+			rr := httptest.NewRecorder()
+			srv.ServeHTTP(rr, req)
+
+			got := rr.Header().Get(middleware.RequestIDHeader)
+			if got == "" {
+				t.Fatal("expected X-Request-ID response header")
+			}
+			if tc.keep && got != tc.inbound {
+				t.Errorf("expected request id %q, got %q", tc.inbound, got)
+			}
+			if !tc.keep && got == tc.inbound {
+				t.Errorf("expected generated request id, got inbound %q", got)
+			}
+			if ctxRequestID != got {
+				t.Errorf("expected request id %q in the context, got %q", got, ctxRequestID)
+			}
+		})
+	}
+}
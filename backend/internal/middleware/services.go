@@ -4,9 +4,9 @@ import (
 	"backend/internal/contexthelper"
 	"database/sql"
 	"net/http"
-	amqp "github.com/rabbitmq/amqp091-go"
+	"backend/pkg/rabbitmq"
 )
-func WithServices(db *sql.DB, rabbitConn *amqp.Connection) func(http.Handler) http.Handler {
+func WithServices(db *sql.DB, rabbitConn *rabbitmq.Connection) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			ctx := contexthelper.SetServices(r.Context(), db, rabbitConn)
@@ -20,10 +20,13 @@ const (
 	ConfirmationTokenTypeRegister      = "register"
 	ConfirmationTokenTypeEmailChange   = "email_change"
 	ConfirmationTokenTypePasswordChange = "password_change"
+	ConfirmationTokenTypeAccountDeletion = "account_deletion"
 
+	// NEW tokens wait for their email or, once it was delivered, for the confirmation
 	ConfirmationTokenStatusNew        = "NEW"
 	ConfirmationTokenStatusExpired    = "EXPIRED"
 	ConfirmationTokenStatusCanceled   = "CANCELED"
+	// PROCESSING while the email with the token is being sent, FAILED when it could not be delivered
 	ConfirmationTokenStatusProcessing = "PROCESSING"
 	ConfirmationTokenStatusFailed     = "FAILED"
 	ConfirmationTokenStatusConsumed   = "CONSUMED"
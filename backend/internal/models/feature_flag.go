@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// FeatureFlag switches a feature at runtime. RolloutPercent enables it for a stable share
+// of the users only.
+type FeatureFlag struct {
+	Name           string    `db:"name" json:"name"`
+	Enabled        bool      `db:"enabled" json:"enabled"`
+	RolloutPercent int       `db:"rollout_percent" json:"rollout_percent"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// FeatureFlagOverride enables or disables a flag for one user, regardless of the rollout.
+type FeatureFlagOverride struct {
+	FlagName string `db:"flag_name" json:"flag_name"`
+	UserId   uint   `db:"user_id" json:"user_id"`
+	Enabled  bool   `db:"enabled" json:"enabled"`
+}
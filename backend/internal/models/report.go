@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type Report struct {
+	Id         uint      `db:"id" json:"id"`
+	UserId     uint      `db:"user_id" json:"user_id"`
+	Type       string    `db:"type" json:"type"`
+	Format     string    `db:"format" json:"format"`
+	Params     string    `db:"params" json:"params"`
+	Status     string    `db:"status" json:"status"`
+	OutputPath string    `db:"output_path" json:"-"`
+	Error      string    `db:"error" json:"error,omitempty"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	StartedAt  time.Time `db:"started_at" json:"started_at"`
+	FinishedAt time.Time `db:"finished_at" json:"finished_at"`
+}
+
+const (
+	ReportStatusQueued  = "queued"
+	ReportStatusRunning = "running"
+	ReportStatusDone    = "done"
+	ReportStatusFailed  = "failed"
+
+	ReportFormatCsv  = "csv"
+	ReportFormatXlsx = "xlsx"
+)
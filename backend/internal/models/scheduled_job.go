@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// ScheduledJob is a task waiting to be published to its queue at RunAt.
+type ScheduledJob struct {
+	Id          uint      `db:"id" json:"id"`
+	Queue       string    `db:"queue" json:"queue"`
+	Task        string    `db:"task" json:"task"`
+	Data        string    `db:"data" json:"data"`
+	Headers     string    `db:"headers" json:"headers"`
+	RunAt       time.Time `db:"run_at" json:"run_at"`
+	PublishedAt time.Time `db:"published_at" json:"published_at"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
@@ -1,6 +1,9 @@
 package payload
 
-import "backend/internal/models"
+import (
+	"backend/internal/models"
+	"time"
+)
 
 type RegisterPayload struct {
 	models.User
@@ -16,6 +19,10 @@ type PasswordChangePayload struct {
 	NewPassword string `json:"new_password"`
 }
 
+type AccountDeletionPayload struct {
+	ScheduledFor time.Time `json:"scheduled_for"`
+}
+
 
 func (rp RegisterPayload) ToUser() models.User {
 	u := rp.User
@@ -0,0 +1,92 @@
+package queue
+
+import (
+	"backend/internal/contexthelper"
+	"backend/internal/repository"
+	"backend/pkg/logger"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+const (
+	accountPurgeBatchSize       = 100
+	defaultPurgeIntervalMinutes = 60
+)
+
+// PurgeDeletedAccountsTask is run by the scheduler every account_deletion.purge_interval_minutes.
+var PurgeDeletedAccountsTask = Register(MaintenanceQueue, "purge_deleted_accounts", purgeDeletedAccounts)
+
+// purgeDeletedAccounts removes every account that is due for deletion, together with its
+// settings, sessions, confirmation tokens (including the register token holding its data),
+// reports and feature flag overrides.
+func purgeDeletedAccounts(ctx context.Context, _ struct{}) error {
+	db := contexthelper.GetDb(ctx)
+	if db == nil {
+		return errors.New("failed to get db")
+	}
+	userRepo := repository.NewUserRepository(db)
+	for {
+		ids, err := userRepo.GetIdsDueForDeletion(ctx, accountPurgeBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := purgeAccount(ctx, id); err != nil {
+				return err
+			}
+		}
+		if len(ids) < accountPurgeBatchSize {
+			return nil
+		}
+	}
+}
+
+func purgeAccount(ctx context.Context, userId uint) error {
+	db := contexthelper.GetDb(ctx)
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	user, err := repository.NewUserRepository(tx).GetById(ctx, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.InfoCtx(ctx, "Account %d was already purged", userId)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := repository.NewUserSessionsRepository(tx).DeleteByUserId(ctx, userId); err != nil {
+		return err
+	}
+	if err := repository.NewUserSettingsRepository(tx).DeleteByUserId(ctx, userId); err != nil {
+		return err
+	}
+	if err := repository.NewConfirmationTokenRepository(tx).DeleteByUser(ctx, userId, user.Email); err != nil {
+		return err
+	}
+	if err := repository.NewReportRepository(tx).DeleteByUserId(ctx, userId); err != nil {
+		return err
+	}
+	if err := repository.NewFeatureFlagRepository(tx).DeleteOverridesByUserId(ctx, userId); err != nil {
+		return err
+	}
+	deleted, err := repository.NewUserRepository(tx).DeleteScheduled(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		// Deletion was cancelled meanwhile – keep the related data untouched
+		logger.InfoCtx(ctx, "Account %d is no longer scheduled for deletion", userId)
+		return nil
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	RemoveUserDataExports(ctx, userId)
+	RemoveUserReports(ctx, userId)
+	logger.InfoCtx(ctx, "🗑 Account %d purged", userId)
+	return nil
+}
@@ -0,0 +1,87 @@
+package queue
+
+import (
+	"backend/config"
+	"backend/internal/contexthelper"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestPurgeAccount(t *testing.T) {
+	tests := []struct {
+		name string
+		mock func(m sqlmock.Sqlmock)
+	}{
+		{
+			name: "purged",
+			mock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT .* FROM users WHERE id").WithArgs(7).WillReturnRows(
+					sqlmock.NewRows([]string{"id", "name", "email", "password", "registered_at", "confirmed_at"}).
+						AddRow(7, "testuser", "test@example.com", "hash", time.Now(), time.Now()),
+				)
+				m.ExpectExec("DELETE FROM user_sessions").WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectExec("DELETE FROM user_settings").WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+				// The register token has no user id when it was confirmed before AssignUser
+				m.ExpectExec(`DELETE FROM confirmation_tokens WHERE \(user_id = \? OR \(user_id = 0 AND type = 'register' AND JSON_UNQUOTE\(JSON_EXTRACT\(payload, '\$\.email'\)\) = \?\)\)`).
+					WithArgs(7, "test@example.com").WillReturnResult(sqlmock.NewResult(0, 2))
+				m.ExpectExec("DELETE FROM reports").WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectExec("DELETE FROM feature_flag_overrides").WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectExec("DELETE FROM users").WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+		},
+		{
+			name: "deletion cancelled meanwhile",
+			mock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT .* FROM users WHERE id").WithArgs(7).WillReturnRows(
+					sqlmock.NewRows([]string{"id", "name", "email", "password", "registered_at", "confirmed_at"}).
+						AddRow(7, "testuser", "test@example.com", "hash", time.Now(), time.Now()),
+				)
+				m.ExpectExec("DELETE FROM user_sessions").WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectExec("DELETE FROM user_settings").WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectExec("DELETE FROM confirmation_tokens").WillReturnResult(sqlmock.NewResult(0, 2))
+				m.ExpectExec("DELETE FROM reports").WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectExec("DELETE FROM feature_flag_overrides").WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectExec("DELETE FROM users").WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectRollback()
+			},
+		},
+		{
+			name: "already purged",
+			mock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectQuery("SELECT .* FROM users WHERE id").WithArgs(7).WillReturnError(sql.ErrNoRows)
+				m.ExpectRollback()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create sqlmock: %v", err)
+			}
+			defer db.Close()
+			tt.mock(mock)
+
+			ctx := contexthelper.SetDb(context.Background(), db)
+			ctx = contexthelper.SetConfig(ctx, &config.Config{
+				DataExport: config.DataExportConfig{StorageDir: t.TempDir()},
+				Reports:    config.ReportsConfig{StorageDir: t.TempDir()},
+			})
+			if err := purgeAccount(ctx, 7); err != nil {
+				t.Fatalf("purgeAccount() error = %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
@@ -1,64 +1,67 @@
 package queue
 
 import (
+	"backend/internal/tracing"
 	"backend/pkg/logger"
-	"encoding/json"
+	"backend/pkg/rabbitmq"
 	"context"
+	"encoding/json"
 	"fmt"
-	"math"
 	"time"
-	"os"
 
+	"github.com/google/uuid"
 	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
-const maxMqIntervalSeconds = 30
-
+// QueueEvent is the body of a task message. Id is set when the task is first published and
+// kept on retries and replays, so the processed-message ledger recognises redeliveries.
 type QueueEvent struct {
+	Id      string          `json:"id"`
 	Task    string          `json:"task"`
 	Data    json.RawMessage `json:"data"`
 	Retries int             `json:"retries"`
 }
 
+// attributeTask is the span attribute of the task name.
+const attributeTask = attribute.Key("messaging.task")
 
-func ConnectWithRetry(ctx context.Context, url string) (*amqp.Connection, error) {
-	var attempt int
-	for {
-		conn, err := amqp.Dial(url)
-		if err == nil {
-			logger.Info("Połączono z RabbitMQ")
-			return conn, nil
-		}
-
-		attempt++
-		backoff := time.Duration(math.Min(math.Pow(2, float64(attempt)), maxMqIntervalSeconds)) * time.Second
-		logger.Warn("Błąd połączenia z RabbitMQ (próba %d): %v. Ponawiam za %v", attempt, err, backoff)
-
-		select {
-		case <-ctx.Done():
-			return nil, fmt.Errorf("timeout podczas łączenia z RabbitMQ: %w", err)
-		case <-time.After(backoff):
-		}
+// publishJSON publishes the event to the queue and waits for the broker confirmation.
+// msg may carry headers; the message ID is the event ID, generated when empty. The trace
+// of the context is propagated in the headers, so the consumer continues it.
+func publishJSON(ctx context.Context, rabbitConn *rabbitmq.Connection, queueName string, event QueueEvent, msg amqp.Publishing) (err error) {
+	if event.Id == "" {
+		event.Id = msg.MessageId
 	}
-}
+	if event.Id == "" {
+		event.Id = uuid.NewString()
+	}
+	msg.MessageId = event.Id
 
+	ctx, span := tracing.Tracer().Start(ctx, "publish "+queueName, trace.WithSpanKind(trace.SpanKindProducer), trace.WithAttributes(
+		semconv.MessagingSystemRabbitmq,
+		semconv.MessagingDestinationName(queueName),
+		semconv.MessagingMessageID(event.Id),
+		attributeTask.String(event.Task),
+	))
+	defer func() { tracing.End(span, err) }()
+	if msg.Headers == nil {
+		msg.Headers = amqp.Table{}
+	}
+	injectTrace(ctx, msg.Headers)
 
-func publishJSON(ch *amqp.Channel, queueName string, event QueueEvent) {
 	body, err := json.Marshal(event)
 	if err != nil {
-		logger.Error("Marshal error: %v", err)
-		os.Exit(1)
-	}
-	err = ch.Publish("", queueName, false, false, amqp.Publishing{
-		ContentType: "application/json",
-		Body:        body,
-	})
-	if err != nil {
-		logger.Error("Publish error: %v", err)
+		return fmt.Errorf("marshal %s event: %w", event.Task, err)
 	}
+	msg.ContentType = "application/json"
+	msg.Body = body
+	return rabbitConn.Publish(ctx, "", queueName, msg)
 }
 
-func setupQueues(ch *amqp.Channel, mainQueue, retryQueue, dlqQueue string, retryDelay int32) error {
+func setupQueues(ch *amqp.Channel, mainQueue, dlqQueue string, retryQueues map[string]time.Duration) error {
 	// DLQ
 	_, err := ch.QueueDeclare(
 		dlqQueue, true, false, false, false, nil,
@@ -68,18 +71,20 @@ func setupQueues(ch *amqp.Channel, mainQueue, retryQueue, dlqQueue string, retry
 		return err
 	}
 
-	// Retry Queue
-	_, err = ch.QueueDeclare(
-		retryQueue, true, false, false, false,
-		amqp.Table{
-			"x-dead-letter-exchange":    "",
-			"x-dead-letter-routing-key": mainQueue,
-			"x-message-ttl":             retryDelay,
-		},
-	)
-	if err != nil {
-		logger.Error("Failed to declare Retry Queue: %v", err)
-		return err
+	// Retry Queues, one per backoff delay
+	for retryQueue, retryDelay := range retryQueues {
+		_, err = ch.QueueDeclare(
+			retryQueue, true, false, false, false,
+			amqp.Table{
+				"x-dead-letter-exchange":    "",
+				"x-dead-letter-routing-key": mainQueue,
+				"x-message-ttl":             int32(retryDelay.Milliseconds()),
+			},
+		)
+		if err != nil {
+			logger.Error("Failed to declare Retry Queue %s: %v", retryQueue, err)
+			return err
+		}
 	}
 
 	// Main Queue
@@ -94,6 +99,6 @@ func setupQueues(ch *amqp.Channel, mainQueue, retryQueue, dlqQueue string, retry
 		logger.Error("Failed to declare Main Queue: %v", err)
 		return err
 	}
-	logger.Debug("Queues setup completed: %s, %s, %s", mainQueue, retryQueue, dlqQueue)
+	logger.Debug("Queues setup completed: %s, %d retry queues, %s", mainQueue, len(retryQueues), dlqQueue)
 	return nil
 }
@@ -0,0 +1,253 @@
+package queue
+
+import (
+	"backend/internal/contexthelper"
+	"backend/internal/metrics"
+	"backend/internal/tracing"
+	"backend/pkg/logger"
+	"backend/pkg/rabbitmq"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// consumerRestartDelay bounds the wait before a consumer whose channel was closed without
+// a reconnect (e.g. by a channel level error) tries again.
+const consumerRestartDelay = 5 * time.Second
+
+// Headers describing the last failure of a task republished to the retry queue or DLQ.
+const (
+	HeaderTaskError = "x-task-error"
+	HeaderFailedAt  = "x-failed-at"
+)
+
+// Consume runs q.Workers consume loops of the task queue until the context is cancelled
+// or the consumer is stopped. Failed tasks are republished to the retry queue of their
+// attempt and, once the retry policy is exhausted, to the dead letter queue. When the
+// channel or the connection is lost, consuming is restarted as soon as the connection
+// is re-established.
+func (c *Consumer) Consume(ctx context.Context, q *TaskQueue) error {
+	rabbitConn := contexthelper.GetRabbitConn(ctx)
+	if rabbitConn == nil {
+		logger.ErrorCtx(ctx, "Failed to get rabbit connection")
+		return errors.New("failed to get rabbit connection")
+	}
+	handlerCtx, cancel := c.handlerContext(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < max(q.Workers, 1); i++ {
+		if !c.startWorker() {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer c.workers.Done()
+			defer wg.Done()
+			c.runWorker(ctx, handlerCtx, rabbitConn, q, fmt.Sprintf("%s-%d", q.Name, i+1))
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// runWorker keeps one consume loop of the task queue running until the context is
+// cancelled, the consumer is stopped or the connection is closed for good.
+func (c *Consumer) runWorker(ctx, handlerCtx context.Context, rabbitConn *rabbitmq.Connection, q *TaskQueue, tag string) {
+	for {
+		reconnected := rabbitConn.Reconnected()
+		err := c.consume(ctx, handlerCtx, rabbitConn, q, tag)
+		if err == nil {
+			return
+		}
+		logger.WarnCtx(ctx, "%s consumer interrupted: %v", tag, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.stopping:
+			return
+		case <-rabbitConn.Done():
+			return
+		case <-reconnected:
+		case <-time.After(consumerRestartDelay):
+		}
+		logger.InfoCtx(ctx, "🔁 Restarting %s consumer", tag)
+	}
+}
+
+// consume processes deliveries until the context is cancelled, the consumer is stopped
+// (both return nil) or the delivery channel is closed, which always happens together
+// with the AMQP channel or connection.
+func (c *Consumer) consume(ctx, handlerCtx context.Context, rabbitConn *rabbitmq.Connection, q *TaskQueue, tag string) error {
+	ch, err := rabbitConn.Channel()
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to open channel: %v", err)
+		return err
+	}
+	defer ch.Close()
+
+	if err := q.setup(ch); err != nil {
+		return err
+	}
+	if err := ch.Qos(max(q.Prefetch, 1), 0, false); err != nil {
+		logger.ErrorCtx(ctx, "Failed to set %s prefetch: %v", q.Name, err)
+		return err
+	}
+
+	msgs, err := ch.Consume(q.Queues.Main, tag, false, false, false, false, nil)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to register %s consumer: %v", tag, err)
+		return err
+	}
+
+	logger.InfoCtx(ctx, "📥 %s consumer started...", tag)
+
+	for {
+		// A stop takes precedence over deliveries that are already waiting
+		select {
+		case <-ctx.Done():
+			logger.InfoCtx(ctx, "⏹ %s consumer stopped by context", tag)
+			drainDeliveries(ctx, ch, tag, msgs)
+			return nil
+		case <-c.stopping:
+			logger.InfoCtx(ctx, "⏹ %s consumer stopped", tag)
+			drainDeliveries(ctx, ch, tag, msgs)
+			return nil
+		default:
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-c.stopping:
+		case d, ok := <-msgs:
+			if !ok {
+				logger.InfoCtx(ctx, "📭 %s queue closed", q.Name)
+				return errors.New("delivery channel closed")
+			}
+			c.handleDelivery(handlerCtx, rabbitConn, q, d)
+		}
+	}
+}
+
+// drainDeliveries cancels the consumer so the broker stops sending deliveries and
+// requeues the prefetched ones that were not started yet.
+func drainDeliveries(ctx context.Context, ch *amqp.Channel, tag string, msgs <-chan amqp.Delivery) {
+	if err := ch.Cancel(tag, false); err != nil {
+		// The channel is closed, so its unacknowledged deliveries are requeued by the broker
+		logger.WarnCtx(ctx, "Failed to cancel %s consumer: %v", tag, err)
+		return
+	}
+	requeued := 0
+	for d := range msgs {
+		d.Nack(false, true)
+		requeued++
+	}
+	if requeued > 0 {
+		logger.InfoCtx(ctx, "↩️ %s consumer requeued %d prefetched message(s)", tag, requeued)
+	}
+}
+
+// handleDelivery handles a single delivery and acknowledges it once it has been handled
+// or republished to the retry queue or DLQ. It is recorded as a span continuing the trace
+// of the publisher.
+func (c *Consumer) handleDelivery(ctx context.Context, rabbitConn *rabbitmq.Connection, q *TaskQueue, d amqp.Delivery) {
+	ctx = withOrigin(ctx, d.Headers)
+	ctx, span := tracing.Tracer().Start(extractTrace(ctx, d.Headers), "process "+q.Name, trace.WithSpanKind(trace.SpanKindConsumer), trace.WithAttributes(
+		semconv.MessagingSystemRabbitmq,
+		semconv.MessagingDestinationName(q.Queues.Main),
+		semconv.MessagingMessageID(d.MessageId),
+	))
+	defer span.End()
+	var event QueueEvent
+	if err := json.Unmarshal(d.Body, &event); err != nil {
+		logger.ErrorCtx(ctx, "❌ Invalid %s task: %v", q.Name, err)
+		span.SetStatus(codes.Error, "invalid task")
+		metrics.QueueConsumedTotal.WithLabelValues(q.Name, metrics.ResultInvalid).Inc()
+		d.Ack(false)
+		return
+	}
+	if event.Id == "" {
+		// Published before event IDs were introduced
+		event.Id = d.MessageId
+	}
+	processed, err := isProcessed(ctx, event)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to check processed %s message %s: %v", q.Name, event.Id, err)
+	}
+	if processed {
+		logger.WarnCtx(ctx, "⏭ Skipping duplicate %s task %s (message %s)", q.Name, event.Task, event.Id)
+		metrics.QueueConsumedTotal.WithLabelValues(q.Name, metrics.ResultDuplicate).Inc()
+		d.Ack(false)
+		return
+	}
+	ctx = withDelivery(ctx, event, event.Retries >= q.Retry.MaxRetries)
+	span.SetName("process " + q.Name + " " + event.Task)
+	span.SetAttributes(attributeTask.String(event.Task), attribute.Int("messaging.retries", event.Retries))
+
+	logger.InfoCtx(ctx, "🔄 Handling %s task: %s", q.Name, event.Task)
+	if err := q.handle(ctx, event); err != nil {
+		if ctx.Err() != nil {
+			// Aborted by Stop – the task did not fail, let another consumer run it again
+			logger.WarnCtx(ctx, "↩️ %s task %s aborted on shutdown, requeueing", q.Name, event.Task)
+			metrics.QueueConsumedTotal.WithLabelValues(q.Name, metrics.ResultAborted).Inc()
+			d.Nack(false, true)
+			return
+		}
+		logger.ErrorCtx(ctx, "Failed to handle %s task %s: %v", q.Name, event.Task, err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		metrics.QueueConsumedTotal.WithLabelValues(q.Name, metrics.ResultFailure).Inc()
+
+		event.Retries++
+		target := q.RetryQueue(event.Retries)
+		switch {
+		case isPermanent(err):
+			logger.WarnCtx(ctx, "💀 %s task %s cannot succeed, moving it to the DLQ", q.Name, event.Task)
+			target = q.Queues.DLQ
+		case event.Retries > q.Retry.MaxRetries:
+			logger.WarnCtx(ctx, "💀 %s max retries reached for task %s (message %s, retries %d)", q.Name, event.Task, event.Id, event.Retries)
+			target = q.Queues.DLQ
+		default:
+			logger.WarnCtx(ctx, "🔄 %s retry %d/%d in %s", q.Name, event.Retries, q.Retry.MaxRetries, q.Retry.DelayFor(event.Retries))
+		}
+		msg := amqp.Publishing{
+			MessageId: d.MessageId,
+			Headers: amqp.Table{
+				HeaderTaskError: err.Error(),
+				HeaderFailedAt:  time.Now().UTC().Format(time.RFC3339),
+			},
+		}
+		copyOriginHeaders(d.Headers, msg.Headers)
+		if err := c.republish(ctx, rabbitConn, target, event, msg); err != nil {
+			// Keep the original message rather than losing the task
+			logger.ErrorCtx(ctx, "Failed to republish %s task to %s, requeueing: %v", q.Name, target, err)
+			d.Nack(false, true)
+			return
+		}
+		if target == q.Queues.DLQ {
+			metrics.QueueDeadLetteredTotal.WithLabelValues(q.Name).Inc()
+		} else {
+			metrics.QueueRetriesTotal.WithLabelValues(q.Name).Inc()
+		}
+		d.Ack(false)
+		return
+	}
+
+	if db := contexthelper.GetDb(ctx); db != nil {
+		if err := markProcessed(ctx, db); err != nil {
+			logger.ErrorCtx(ctx, "Failed to record processed %s message %s: %v", q.Name, event.Id, err)
+		}
+	}
+	d.Ack(false)
+	metrics.QueueConsumedTotal.WithLabelValues(q.Name, metrics.ResultSuccess).Inc()
+	logger.InfoCtx(ctx, "✅ %s task handled successfully: %s", q.Name, event.Task)
+}
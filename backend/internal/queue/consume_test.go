@@ -0,0 +1,130 @@
+package queue
+
+import (
+	"backend/pkg/rabbitmq"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type testPayload struct {
+	UserId uint `json:"user_id"`
+}
+
+// acknowledger records how a delivery was settled.
+type acknowledger struct {
+	acked, nacked, requeued bool
+}
+
+func (a *acknowledger) Ack(uint64, bool) error {
+	a.acked = true
+	return nil
+}
+
+func (a *acknowledger) Nack(_ uint64, _ bool, requeue bool) error {
+	a.nacked, a.requeued = true, requeue
+	return nil
+}
+
+func (a *acknowledger) Reject(_ uint64, requeue bool) error {
+	a.nacked, a.requeued = true, requeue
+	return nil
+}
+
+func newTestQueue() *TaskQueue {
+	q := &TaskQueue{
+		Name:     "test",
+		Queues:   QueueSet{Main: "test_tasks", Retry: "test_tasks_retry", DLQ: "test_tasks_dlq"},
+		Retry:    RetryPolicy{MaxRetries: 2, Delay: time.Second, BackoffFactor: 2},
+		handlers: map[string]func(ctx context.Context, data json.RawMessage) error{},
+	}
+	Register(q, "ok", func(context.Context, testPayload) error { return nil })
+	Register(q, "fail", func(context.Context, testPayload) error { return errors.New("smtp unavailable") })
+	return q
+}
+
+func TestRegister_DuplicatePanics(t *testing.T) {
+	q := newTestQueue()
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a task twice to panic")
+		}
+	}()
+	Register(q, "ok", func(context.Context, testPayload) error { return nil })
+}
+
+func TestHandleDelivery(t *testing.T) {
+	tests := []struct {
+		name         string
+		task         string
+		data         string
+		retries      int
+		republishErr error
+		wantTarget   string
+		wantRetries  int
+		wantRequeue  bool
+	}{
+		{name: "success", task: "ok", data: `{"user_id":1}`},
+		{name: "handler error is retried", task: "fail", data: `{"user_id":1}`, wantTarget: "test_tasks_retry_1000ms", wantRetries: 1},
+		{name: "second retry waits longer", task: "fail", data: `{"user_id":1}`, retries: 1, wantTarget: "test_tasks_retry_2000ms", wantRetries: 2},
+		{name: "last retry goes to the DLQ", task: "fail", data: `{"user_id":1}`, retries: 2, wantTarget: "test_tasks_dlq", wantRetries: 3},
+		{name: "undecodable data goes to the DLQ", task: "ok", data: `"not an object"`, wantTarget: "test_tasks_dlq", wantRetries: 1},
+		{name: "unknown task is retried", task: "missing", data: `{}`, wantTarget: "test_tasks_retry_1000ms", wantRetries: 1},
+		{name: "failed republish is requeued", task: "fail", data: `{"user_id":1}`, republishErr: rabbitmq.ErrNotConnected, wantTarget: "test_tasks_retry_1000ms", wantRetries: 1, wantRequeue: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQueue()
+			var target string
+			var republished QueueEvent
+			var headers amqp.Table
+			c := NewConsumer()
+			c.republish = func(_ context.Context, _ *rabbitmq.Connection, queueName string, event QueueEvent, msg amqp.Publishing) error {
+				target, republished, headers = queueName, event, msg.Headers
+				return tt.republishErr
+			}
+			body, _ := json.Marshal(QueueEvent{Task: tt.task, Data: json.RawMessage(tt.data), Retries: tt.retries})
+			ack := &acknowledger{}
+
+			c.handleDelivery(context.Background(), nil, q, amqp.Delivery{Acknowledger: ack, Body: body})
+
+			if target != tt.wantTarget {
+				t.Errorf("expected the task to be republished to %q, got %q", tt.wantTarget, target)
+			}
+			if tt.wantTarget != "" {
+				if republished.Retries != tt.wantRetries {
+					t.Errorf("expected %d retries, got %d", tt.wantRetries, republished.Retries)
+				}
+				if headers[HeaderTaskError] == nil {
+					t.Error("expected the failure reason in the headers")
+				}
+			}
+			if tt.wantRequeue {
+				if !ack.nacked || !ack.requeued || ack.acked {
+					t.Errorf("expected the delivery to be requeued, got %+v", ack)
+				}
+			} else if !ack.acked || ack.nacked {
+				t.Errorf("expected the delivery to be acknowledged, got %+v", ack)
+			}
+		})
+	}
+}
+
+func TestHandleDelivery_InvalidEventIsDropped(t *testing.T) {
+	c := NewConsumer()
+	c.republish = func(context.Context, *rabbitmq.Connection, string, QueueEvent, amqp.Publishing) error {
+		t.Error("expected an invalid message not to be republished")
+		return nil
+	}
+	ack := &acknowledger{}
+
+	c.handleDelivery(context.Background(), nil, newTestQueue(), amqp.Delivery{Acknowledger: ack, Body: []byte("{")})
+
+	if !ack.acked {
+		t.Error("expected the invalid message to be acknowledged")
+	}
+}
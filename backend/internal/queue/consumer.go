@@ -1,9 +1,86 @@
 package queue
 
+import (
+	"backend/pkg/rabbitmq"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ErrStopTimeout is returned by Consumer.Stop when in-flight tasks did not finish in time.
+var ErrStopTimeout = errors.New("consumer stop timed out")
+
+// Consumer runs the consume loops of the task queues and background workers. Tasks are
+// handled with a context that is not cancelled together with the context passed to
+// Consume, so a shutdown signal does not interrupt a task halfway; Stop drains them.
 type Consumer struct {
+	mu       sync.Mutex
+	stopped  bool
+	stopping chan struct{}
+	workers  sync.WaitGroup
+	abortCtx context.Context
+	abort    context.CancelFunc
+	// republish sends failed tasks to their retry queue or DLQ.
+	republish func(ctx context.Context, rabbitConn *rabbitmq.Connection, queueName string, event QueueEvent, msg amqp.Publishing) error
 }
 
 func NewConsumer() *Consumer {
+	abortCtx, abort := context.WithCancel(context.Background())
 	return &Consumer{
+		stopping:  make(chan struct{}),
+		abortCtx:  abortCtx,
+		abort:     abort,
+		republish: publishJSON,
+	}
+}
+
+// Stop stops consuming: no new deliveries are accepted, prefetched ones are requeued and
+// the in-flight tasks get up to timeout to finish. Tasks still running after the timeout
+// have their context cancelled and are requeued, and ErrStopTimeout is returned.
+func (c *Consumer) Stop(timeout time.Duration) error {
+	c.mu.Lock()
+	if !c.stopped {
+		c.stopped = true
+		close(c.stopping)
+	}
+	c.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		c.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		c.abort()
+		return ErrStopTimeout
+	}
+}
+
+// startWorker registers a worker goroutine waited for by Stop. It returns false once the
+// consumer is stopping.
+func (c *Consumer) startWorker() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopped {
+		return false
+	}
+	c.workers.Add(1)
+	return true
+}
+
+// handlerContext returns the context tasks are handled with. It keeps the values of ctx
+// but is cancelled only when Stop gives up waiting for the in-flight tasks.
+func (c *Consumer) handlerContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	handlerCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stopAbort := context.AfterFunc(c.abortCtx, cancel)
+	return handlerCtx, func() {
+		stopAbort()
+		cancel()
 	}
 }
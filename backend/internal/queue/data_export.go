@@ -0,0 +1,249 @@
+package queue
+
+import (
+	"archive/zip"
+	"backend/internal/contexthelper"
+	"backend/internal/email"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/pkg/logger"
+	"backend/pkg/signedlink"
+	"backend/pkg/uuidstr"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+const (
+	dataExportFilePrefix = "export_"
+	dataExportFileExt    = ".zip"
+	dataExportDateFormat = "2006-01-02 15:04:05"
+)
+
+var dataExportFileNameRegex = regexp.MustCompile(`^` + dataExportFilePrefix + `\d+_[a-z0-9]+\` + dataExportFileExt + `$`)
+
+type DataExportReportData struct {
+	UserId uint `json:"user_id"`
+}
+
+type dataExportProfile struct {
+	Id           uint   `json:"id"`
+	Name         string `json:"name"`
+	Email        string `json:"email"`
+	RegisteredAt string `json:"registered_at"`
+	ConfirmedAt  string `json:"confirmed_at"`
+}
+
+type dataExportSession struct {
+	CreatedAt   string `json:"created_at"`
+	ExpiresAt   string `json:"expires_at"`
+	RefreshedAt string `json:"refreshed_at,omitempty"`
+	RevokedAt   string `json:"revoked_at,omitempty"`
+	UserAgent   string `json:"user_agent"`
+	Ip          string `json:"ip"`
+}
+
+type dataExportToken struct {
+	Type            string          `json:"type"`
+	Status          string          `json:"status"`
+	Payload         json.RawMessage `json:"payload"`
+	CreatedAt       string          `json:"created_at"`
+	ExpiresAt       string          `json:"expires_at"`
+	StatusChangedAt string          `json:"status_changed_at"`
+}
+
+// IsDataExportFileName reports whether name is a file name generated by the data export task.
+func IsDataExportFileName(name string) bool {
+	return dataExportFileNameRegex.MatchString(name)
+}
+
+var DataExportTask = Register(ReportQueue, "build_data_export", buildDataExport)
+
+func buildDataExport(ctx context.Context, data DataExportReportData) error {
+	if data.UserId == 0 {
+		return errors.New("empty user id")
+	}
+	cfg := contexthelper.GetConfig(ctx)
+	db := contexthelper.GetDb(ctx)
+
+	user, err := repository.NewUserRepository(db).GetById(ctx, data.UserId)
+	if err != nil {
+		return err
+	}
+	settings, err := repository.NewUserSettingsRepository(db).GetByUserId(ctx, user.Id)
+	if err != nil {
+		return err
+	}
+	sessions, err := repository.NewUserSessionsRepository(db).GetByUserId(ctx, user.Id)
+	if err != nil {
+		return err
+	}
+	tokens, err := repository.NewConfirmationTokenRepository(db).GetByUser(ctx, user.Id, user.Email)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(cfg.DataExport.StorageDir, 0750); err != nil {
+		return err
+	}
+	linkTtl := dataExportLinkTtl(ctx)
+	removeExpiredDataExports(ctx, cfg.DataExport.StorageDir, linkTtl)
+
+	fileName := fmt.Sprintf("%s%d_%s%s", dataExportFilePrefix, user.Id, uuidstr.GetUniqBase36(32), dataExportFileExt)
+	files := map[string]any{
+		"profile.json":  exportProfile(user),
+		"settings.json": settings,
+		"sessions.json": exportSessions(sessions),
+		"tokens.json":   exportTokens(tokens),
+	}
+	if err := writeDataExportArchive(filepath.Join(cfg.DataExport.StorageDir, fileName), files); err != nil {
+		return err
+	}
+	logger.InfoCtx(ctx, "📦 Data export for user %d stored as %s", user.Id, fileName)
+
+	sender := email.GetEmailSender(ctx)
+	if sender == nil {
+		return errors.New("failed to get email sender")
+	}
+	expiresAt := time.Now().Add(linkTtl)
+	query := signedlink.Sign(cfg.Token.JwtSecret, fileName, expiresAt)
+	link := fmt.Sprintf("%s/api/export/%s?%s", cfg.Frontend.BaseURL, fileName, query.Encode())
+	err = sender.SendDataExportEmail(ctx, user.Email, user.Name, getUserLangCode(ctx, db, user.Id), link, expiresAt)
+	if err != nil {
+		return err
+	}
+	logger.InfoCtx(ctx, "Data export email sent to %s", logger.Email(user.Email))
+	return nil
+}
+
+// RemoveUserDataExports deletes all export archives generated for the given user.
+func RemoveUserDataExports(ctx context.Context, userId uint) {
+	cfg := contexthelper.GetConfig(ctx)
+	pattern := filepath.Join(cfg.DataExport.StorageDir, fmt.Sprintf("%s%d_*%s", dataExportFilePrefix, userId, dataExportFileExt))
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to list data exports of user %d: %v", userId, err)
+		return
+	}
+	for _, f := range files {
+		if err := os.Remove(f); err != nil {
+			logger.ErrorCtx(ctx, "Failed to remove data export %s: %v", f, err)
+		}
+	}
+}
+
+func dataExportLinkTtl(ctx context.Context) time.Duration {
+	cfg := contexthelper.GetConfig(ctx)
+	return time.Duration(cfg.DataExport.LinkTtlHours) * time.Hour
+}
+
+func removeExpiredDataExports(ctx context.Context, dir string, ttl time.Duration) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to read data export directory: %v", err)
+		return
+	}
+	for _, entry := range entries {
+		if !IsDataExportFileName(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < ttl {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			logger.ErrorCtx(ctx, "Failed to remove expired data export %s: %v", entry.Name(), err)
+		}
+	}
+}
+
+func writeDataExportArchive(path string, files map[string]any) error {
+	tmpPath := path + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			f.Close()
+			os.Remove(tmpPath)
+			return err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(content); err != nil {
+			f.Close()
+			os.Remove(tmpPath)
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func exportProfile(user models.User) dataExportProfile {
+	return dataExportProfile{
+		Id:           user.Id,
+		Name:         user.Name,
+		Email:        user.Email,
+		RegisteredAt: user.RegisteredAt.Format(dataExportDateFormat),
+		ConfirmedAt:  user.ConfirmedAt.Format(dataExportDateFormat),
+	}
+}
+
+func exportSessions(sessions []models.UserSessions) []dataExportSession {
+	list := make([]dataExportSession, 0, len(sessions))
+	for _, s := range sessions {
+		list = append(list, dataExportSession{
+			CreatedAt:   s.CreatedAt.Format(dataExportDateFormat),
+			ExpiresAt:   s.ExpiresAt.Format(dataExportDateFormat),
+			RefreshedAt: formatOptionalTime(s.RefreshedAt),
+			RevokedAt:   formatOptionalTime(s.RevokedAt),
+			UserAgent:   s.UserAgent,
+			Ip:          s.Ip,
+		})
+	}
+	return list
+}
+
+// exportTokens returns the token history without the token values themselves.
+func exportTokens(tokens []models.ConfirmationToken) []dataExportToken {
+	list := make([]dataExportToken, 0, len(tokens))
+	for _, t := range tokens {
+		payload := t.Payload
+		if payload == "" {
+			payload = "{}"
+		}
+		list = append(list, dataExportToken{
+			Type:            t.Type,
+			Status:          t.Status,
+			Payload:         json.RawMessage(payload),
+			CreatedAt:       t.CreatedAt.Format(dataExportDateFormat),
+			ExpiresAt:       t.ExpiresAt.Format(dataExportDateFormat),
+			StatusChangedAt: t.StatusChangedAt.Format(dataExportDateFormat),
+		})
+	}
+	return list
+}
+
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(dataExportDateFormat)
+}
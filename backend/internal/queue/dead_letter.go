@@ -0,0 +1,139 @@
+package queue
+
+import (
+	"backend/pkg/logger"
+	"backend/pkg/rabbitmq"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DeadLetter is a task that exhausted its retries and waits in the dead letter queue.
+type DeadLetter struct {
+	Id        string          `json:"id"`
+	Task      string          `json:"task"`
+	Retries   int             `json:"retries"`
+	LastError string          `json:"last_error,omitempty"`
+	FailedAt  string          `json:"failed_at,omitempty"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// ListDeadLetters returns up to limit messages from the head of the dead letter queue
+// without removing them.
+func ListDeadLetters(ctx context.Context, rabbitConn *rabbitmq.Connection, q *TaskQueue, limit int) ([]DeadLetter, error) {
+	ch, err := rabbitConn.Channel()
+	if err != nil {
+		return nil, err
+	}
+	// Closing the channel returns the fetched, unacknowledged messages to the queue
+	defer ch.Close()
+	if err := q.setup(ch); err != nil {
+		return nil, err
+	}
+
+	list := []DeadLetter{}
+	for len(list) < limit {
+		d, ok, err := ch.Get(q.Queues.DLQ, false)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		list = append(list, newDeadLetter(d))
+	}
+	return list, nil
+}
+
+// ReplayDeadLetters moves the dead letters with the given IDs (all of them when ids is
+// empty) back to the main queue with their retry counter reset. It returns the number
+// of replayed messages.
+func ReplayDeadLetters(ctx context.Context, rabbitConn *rabbitmq.Connection, q *TaskQueue, ids []string) (int, error) {
+	ch, err := rabbitConn.Channel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+	if err := q.setup(ch); err != nil {
+		return 0, err
+	}
+	dlq, err := ch.QueueDeclarePassive(q.Queues.DLQ, true, false, false, false, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	selected := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		selected[id] = true
+	}
+	replayed := 0
+	// Skipped messages stay unacknowledged until the channel is closed, so every message
+	// present at the start is visited at most once.
+	for i := 0; i < dlq.Messages; i++ {
+		d, ok, err := ch.Get(q.Queues.DLQ, false)
+		if err != nil {
+			return replayed, err
+		}
+		if !ok {
+			break
+		}
+		dl := newDeadLetter(d)
+		if len(selected) > 0 && !selected[dl.Id] {
+			continue
+		}
+		var event QueueEvent
+		if err := json.Unmarshal(d.Body, &event); err != nil {
+			logger.ErrorCtx(ctx, "Skipping invalid dead letter %s: %v", dl.Id, err)
+			continue
+		}
+		event.Retries = 0
+		msg := amqp.Publishing{MessageId: d.MessageId, Headers: amqp.Table{}}
+		copyOriginHeaders(d.Headers, msg.Headers)
+		if err := publishJSON(ctx, rabbitConn, q.Queues.Main, event, msg); err != nil {
+			return replayed, err
+		}
+		if err := d.Ack(false); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+// PurgeDeadLetters removes all messages from the dead letter queue and returns their count.
+func PurgeDeadLetters(ctx context.Context, rabbitConn *rabbitmq.Connection, q *TaskQueue) (int, error) {
+	ch, err := rabbitConn.Channel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+	if err := q.setup(ch); err != nil {
+		return 0, err
+	}
+	return ch.QueuePurge(q.Queues.DLQ, false)
+}
+
+func newDeadLetter(d amqp.Delivery) DeadLetter {
+	dl := DeadLetter{Id: d.MessageId}
+	if dl.Id == "" {
+		// Messages dead-lettered before message IDs were set are identified by content
+		sum := sha256.Sum256(d.Body)
+		dl.Id = hex.EncodeToString(sum[:8])
+	}
+	var event QueueEvent
+	if err := json.Unmarshal(d.Body, &event); err == nil {
+		dl.Task = event.Task
+		dl.Retries = event.Retries
+		dl.Data = event.Data
+	}
+	if reason, ok := d.Headers[HeaderTaskError].(string); ok {
+		dl.LastError = reason
+	}
+	if failedAt, ok := d.Headers[HeaderFailedAt].(string); ok {
+		dl.FailedAt = failedAt
+	}
+	return dl
+}
@@ -8,24 +8,28 @@ import (
 	"backend/internal/repository"
 	"backend/pkg/logger"
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
-
-	amqp "github.com/rabbitmq/amqp091-go"
+	"time"
 )
 
-const (
-	emailMainQueue  = "email_tasks"
-	emailRetryQueue = "email_tasks_retry"
-	emailDLQQueue   = "email_tasks_dlq"
-
-	emailMaxRetries = 3
-	emailRetryDelay = 5000 // ms
+// EmailQueue delivers transactional emails.
+var EmailQueue = newTaskQueue("email", QueueSet{
+	Main:  "email_tasks",
+	Retry: "email_tasks_retry",
+	DLQ:   "email_tasks_dlq",
+}, RetryPolicy{
+	MaxRetries: 3,
+	Delay:      5 * time.Second,
+})
 
-	registerEmailTask      = "send_register_email"
-	emailChangeEmailTask   = "send_email_change_email"
-	passwordResetEmailTask = "send_password_reset_email"
+var (
+	WelcomeEmailTask         = Register(EmailQueue, "send_register_email", sendWelcomeEmail)
+	EmailChangeEmailTask     = Register(EmailQueue, "send_email_change_email", sendEmailChangeEmail)
+	PasswordResetEmailTask   = Register(EmailQueue, "send_password_reset_email", sendPasswordResetEmail)
+	AccountDeletionEmailTask = Register(EmailQueue, "send_account_deletion_email", sendAccountDeletionEmail)
 )
 
 type WelcomeEmailData struct {
@@ -37,60 +41,24 @@ type EmailChangeEmailData struct {
 type PasswordResetEmailData struct {
 	PasswordResetToken string `json:"password_reset_token"`
 }
-
-func (c *Consumer) HandleEmailTask(ctx context.Context, task string, rawMessage json.RawMessage) error {
-	logger.InfoCtx(ctx, "📧 starting handling email task: %s", task)
-
-	switch task {
-	case registerEmailTask:
-		err := c.sendWelcomeEmail(ctx, rawMessage)
-		if err != nil {
-			return err
-		}
-	case emailChangeEmailTask:
-		err := c.sendEmailChangeEmail(ctx, rawMessage)
-		if err != nil {
-			return err
-		}
-	case passwordResetEmailTask:
-		err := c.sendPasswordResetEmail(ctx, rawMessage)
-		if err != nil {
-			return err
-		}
-	default:
-		logger.ErrorCtx(ctx, "❌ Unknown email task: %s", task)
-		return errors.New("unknown email task")
-	}
-	logger.InfoCtx(ctx, "✅ Email task handled successfully")
-	return nil
-}
-
-func setupEmailQueues(ch *amqp.Channel) error {
-	return setupQueues(ch, emailMainQueue, emailRetryQueue, emailDLQQueue, int32(emailRetryDelay))
+type AccountDeletionEmailData struct {
+	AccountDeletionToken string `json:"account_deletion_token"`
 }
 
-func (c *Consumer) sendWelcomeEmail(ctx context.Context, rawMessage json.RawMessage) error {
-	var data WelcomeEmailData
-	if err := json.Unmarshal(rawMessage, &data); err != nil {
-		return err
-	}
-
+func sendWelcomeEmail(ctx context.Context, data WelcomeEmailData) error {
 	if data.RegisterToken == "" {
 		return errors.New("empty register token")
 	}
-	db := contexthelper.GetDb(ctx)
-	confirmationRepo := repository.NewConfirmationTokenRepository(db)
-	ct, err := confirmationRepo.GetActiveNewTokenWithType(ctx, data.RegisterToken, models.ConfirmationTokenTypeRegister)
-	if err != nil {
-		return err
-	}
+	return deliverTokenEmail(ctx, data.RegisterToken, models.ConfirmationTokenTypeRegister, sendWelcomeEmailForToken)
+}
 
+func sendWelcomeEmailForToken(ctx context.Context, db repository.DBExecutor, ct models.ConfirmationToken) error {
 	sender := email.GetEmailSender(ctx)
 	if sender == nil {
 		return errors.New("failed to get email sender")
 	}
 	var payloadData payload.RegisterPayload
-	err = json.Unmarshal([]byte(ct.Payload), &payloadData)
+	err := json.Unmarshal([]byte(ct.Payload), &payloadData)
 	if err != nil {
 		return err
 	}
@@ -109,30 +77,23 @@ func (c *Consumer) sendWelcomeEmail(ctx context.Context, rawMessage json.RawMess
 		langCode = lang.I18nCode
 	}
 
-	link := fmt.Sprintf("%s/confirm/%s", cfg.Frontend.BaseURL, data.RegisterToken)
+	link := fmt.Sprintf("%s/confirm/%s", cfg.Frontend.BaseURL, ct.Token)
 	err = sender.SendWelcomeEmail(ctx, payloadData.Email, payloadData.Name, langCode, link)
 	if err != nil {
 		return err
 	}
-	logger.InfoCtx(ctx, "Email sent to %s", payloadData.Email)
+	logger.InfoCtx(ctx, "Email sent to %s", logger.Email(payloadData.Email))
 	return nil
 }
 
-func (c *Consumer) sendEmailChangeEmail(ctx context.Context, rawMessage json.RawMessage) error {
-	var data EmailChangeEmailData
-	if err := json.Unmarshal(rawMessage, &data); err != nil {
-		return err
-	}
-
+func sendEmailChangeEmail(ctx context.Context, data EmailChangeEmailData) error {
 	if data.EmailChangeToken == "" {
 		return errors.New("empty email change token")
 	}
-	db := contexthelper.GetDb(ctx)
-	confirmationRepo := repository.NewConfirmationTokenRepository(db)
-	ct, err := confirmationRepo.GetActiveNewTokenWithType(ctx, data.EmailChangeToken, models.ConfirmationTokenTypeEmailChange)
-	if err != nil {
-		return err
-	}
+	return deliverTokenEmail(ctx, data.EmailChangeToken, models.ConfirmationTokenTypeEmailChange, sendEmailChangeEmailForToken)
+}
+
+func sendEmailChangeEmailForToken(ctx context.Context, db repository.DBExecutor, ct models.ConfirmationToken) error {
 	if ct.UserId == 0 {
 		return errors.New("invalid user id in token")
 	}
@@ -142,7 +103,7 @@ func (c *Consumer) sendEmailChangeEmail(ctx context.Context, rawMessage json.Raw
 		return errors.New("failed to get email sender")
 	}
 	var payloadData payload.EmailChangePayload
-	err = json.Unmarshal([]byte(ct.Payload), &payloadData)
+	err := json.Unmarshal([]byte(ct.Payload), &payloadData)
 	if err != nil {
 		return err
 	}
@@ -162,31 +123,59 @@ func (c *Consumer) sendEmailChangeEmail(ctx context.Context, rawMessage json.Raw
 		return errors.New("user not found")
 	}
 
-	link := fmt.Sprintf("%s/confirm/%s", cfg.Frontend.BaseURL, data.EmailChangeToken)
+	link := fmt.Sprintf("%s/confirm/%s", cfg.Frontend.BaseURL, ct.Token)
 	err = sender.SendEmailChangeEmail(ctx, payloadData.NewEmail, user.Name, link)
 	if err != nil {
 		return err
 	}
-	logger.InfoCtx(ctx, "Email change email sent to %s", payloadData.NewEmail)
+	logger.InfoCtx(ctx, "Email change email sent to %s", logger.Email(payloadData.NewEmail))
 	return nil
 }
 
-func (c *Consumer) sendPasswordResetEmail(ctx context.Context, rawMessage json.RawMessage) error {
-	var data PasswordResetEmailData
-	if err := json.Unmarshal(rawMessage, &data); err != nil {
+func sendPasswordResetEmail(ctx context.Context, data PasswordResetEmailData) error {
+	if data.PasswordResetToken == "" {
+		return errors.New("empty password reset token")
+	}
+	return deliverTokenEmail(ctx, data.PasswordResetToken, models.ConfirmationTokenTypePasswordChange, sendPasswordResetEmailForToken)
+}
+
+func sendPasswordResetEmailForToken(ctx context.Context, db repository.DBExecutor, ct models.ConfirmationToken) error {
+
+	userRepository := repository.NewUserRepository(db)
+	user, err := userRepository.GetById(ctx, ct.UserId)
+	if err != nil {
 		return err
 	}
+	sender := email.GetEmailSender(ctx)
+	if sender == nil {
+		return errors.New("failed to get email sender")
+	}
 
-	if data.PasswordResetToken == "" {
-		return errors.New("empty password reset token")
+	cfg := contexthelper.GetConfig(ctx)
+
+	link := fmt.Sprintf("%s/reset-password/%s", cfg.Frontend.BaseURL, ct.Token)
+	err = sender.SendPasswordResetEmail(ctx, user.Email, user.Name, link)
+	if err != nil {
+		return err
 	}
-	db := contexthelper.GetDb(ctx)
-	confirmationRepo := repository.NewConfirmationTokenRepository(db)
-	ct, err := confirmationRepo.GetActiveNewTokenWithType(ctx, data.PasswordResetToken, models.ConfirmationTokenTypePasswordChange)
+	logger.InfoCtx(ctx, "Password reset email sent to %s", logger.Email(user.Email))
+	return nil
+}
+
+func sendAccountDeletionEmail(ctx context.Context, data AccountDeletionEmailData) error {
+	if data.AccountDeletionToken == "" {
+		return errors.New("empty account deletion token")
+	}
+	return deliverTokenEmail(ctx, data.AccountDeletionToken, models.ConfirmationTokenTypeAccountDeletion, sendAccountDeletionEmailForToken)
+}
+
+func sendAccountDeletionEmailForToken(ctx context.Context, db repository.DBExecutor, ct models.ConfirmationToken) error {
+	var payloadData payload.AccountDeletionPayload
+	err := json.Unmarshal([]byte(ct.Payload), &payloadData)
 	if err != nil {
 		return err
 	}
-	
+
 	userRepository := repository.NewUserRepository(db)
 	user, err := userRepository.GetById(ctx, ct.UserId)
 	if err != nil {
@@ -198,12 +187,85 @@ func (c *Consumer) sendPasswordResetEmail(ctx context.Context, rawMessage json.R
 	}
 
 	cfg := contexthelper.GetConfig(ctx)
+	langCode := getUserLangCode(ctx, db, user.Id)
 
-	link := fmt.Sprintf("%s/reset-password/%s", cfg.Frontend.BaseURL, data.PasswordResetToken)
-	err = sender.SendPasswordResetEmail(ctx, user.Email, user.Name, link)
+	link := fmt.Sprintf("%s/confirm/%s", cfg.Frontend.BaseURL, ct.Token)
+	err = sender.SendAccountDeletionEmail(ctx, user.Email, user.Name, langCode, link, payloadData.ScheduledFor)
+	if err != nil {
+		return err
+	}
+	logger.InfoCtx(ctx, "Account deletion email sent to %s", logger.Email(user.Email))
+	return nil
+}
+
+// deliverTokenEmail sends the email of a confirmation token with send. The token is
+// PROCESSING while the email is being sent, returns to NEW once it was delivered (recorded
+// in the processed-message ledger in the same transaction) and becomes FAILED when the last
+// attempt fails. Tokens consumed, cancelled or expired in the meantime are skipped.
+func deliverTokenEmail(ctx context.Context, token, tokenType string, send func(ctx context.Context, db repository.DBExecutor, ct models.ConfirmationToken) error) error {
+	db := contexthelper.GetDb(ctx)
+	if db == nil {
+		return errors.New("failed to get db")
+	}
+	confirmationRepo := repository.NewConfirmationTokenRepository(db)
+	ct, err := confirmationRepo.StartDelivery(ctx, token, tokenType)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.WarnCtx(ctx, "Skipping %s email, the token is no longer active", tokenType)
+		return nil
+	}
 	if err != nil {
 		return err
 	}
-	logger.InfoCtx(ctx, "Password reset email sent to %s", user.Email)
+	// Only the hash is stored, the link is built from the token of the task
+	ct.Token = token
+
+	if err := send(ctx, db, ct); err != nil {
+		if isFinalAttempt(ctx) {
+			if failErr := confirmationRepo.FailDelivery(ctx, token); failErr != nil {
+				logger.ErrorCtx(ctx, "Failed to mark %s token as failed: %v", tokenType, failErr)
+			}
+		}
+		return err
+	}
+
+	// The email is out – a failure from here on must not trigger another send
+	if err := finishTokenDelivery(ctx, db, token); err != nil {
+		logger.ErrorCtx(ctx, "Failed to record delivery of %s email: %v", tokenType, err)
+	}
 	return nil
 }
+
+func finishTokenDelivery(ctx context.Context, db *sql.DB, token string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := repository.NewConfirmationTokenRepository(tx).FinishDelivery(ctx, token); err != nil {
+		return err
+	}
+	if err := markProcessed(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// getUserLangCode returns the i18n code of the user's language, falling back to the default language.
+func getUserLangCode(ctx context.Context, db repository.DBExecutor, userId uint) string {
+	cfg := contexthelper.GetConfig(ctx)
+	langCode := cfg.DefaultLanguage
+	us, err := repository.NewUserSettingsRepository(db).GetByUserId(ctx, userId)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to get user settings for user: %d, error: %v", userId, err)
+		return langCode
+	}
+	lang, err := repository.NewLanguageRepository(db).GetById(ctx, us.LangId)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to get language by id: %d, error: %v", us.LangId, err)
+	}
+	if lang.I18nCode > "" {
+		langCode = lang.I18nCode
+	}
+	return langCode
+}
@@ -0,0 +1,90 @@
+package queue
+
+import (
+	"backend/internal/contexthelper"
+	"backend/pkg/validation"
+	"context"
+	"fmt"
+	"strconv"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+)
+
+// Headers carrying the origin of a task, so its logs can be correlated with the request
+// that published it.
+const (
+	HeaderRequestId = "x-request-id"
+	HeaderUserId    = "x-user-id"
+)
+
+// originHeaders returns the request and user ID of the context as message headers.
+func originHeaders(ctx context.Context) amqp.Table {
+	headers := amqp.Table{}
+	if requestId := contexthelper.GetRequestID(ctx); requestId != "" {
+		headers[HeaderRequestId] = requestId
+	}
+	if userId, ok := contexthelper.GetUserId(ctx); ok {
+		headers[HeaderUserId] = strconv.FormatUint(uint64(userId), 10)
+	}
+	return headers
+}
+
+// copyOriginHeaders copies the origin headers of a delivery to the headers of a republished message.
+func copyOriginHeaders(from, to amqp.Table) {
+	for _, key := range []string{HeaderRequestId, HeaderUserId} {
+		if value, ok := from[key]; ok {
+			to[key] = value
+		}
+	}
+}
+
+// headerCarrier adapts message headers to the OpenTelemetry propagator, which stores the
+// trace of the publisher in the traceparent header.
+type headerCarrier amqp.Table
+
+func (c headerCarrier) Get(key string) string {
+	value, ok := c[key]
+	if !ok {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
+
+func (c headerCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// injectTrace adds the trace of the context to the message headers.
+func injectTrace(ctx context.Context, headers amqp.Table) {
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(headers))
+}
+
+// extractTrace returns the context continuing the trace of the publisher.
+func extractTrace(ctx context.Context, headers amqp.Table) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, headerCarrier(headers))
+}
+
+// withOrigin restores the request and user ID of the publisher from the message headers.
+func withOrigin(ctx context.Context, headers amqp.Table) context.Context {
+	if requestId, ok := headers[HeaderRequestId].(string); ok && validation.IsRequestIdValid(requestId) {
+		ctx = contexthelper.SetRequestID(ctx, requestId)
+	}
+	if value, ok := headers[HeaderUserId].(string); ok {
+		if userId, err := strconv.ParseUint(value, 10, 32); err == nil && userId != 0 {
+			ctx = contexthelper.SetUserId(ctx, uint(userId))
+		}
+	}
+	return ctx
+}
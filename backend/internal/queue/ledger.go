@@ -0,0 +1,59 @@
+package queue
+
+import (
+	"backend/internal/contexthelper"
+	"backend/internal/repository"
+	"context"
+	"errors"
+)
+
+type deliveryKey struct{}
+
+// delivery describes the message whose task is being handled.
+type delivery struct {
+	messageId string
+	task      string
+	final     bool
+}
+
+func withDelivery(ctx context.Context, event QueueEvent, final bool) context.Context {
+	return context.WithValue(ctx, deliveryKey{}, delivery{messageId: event.Id, task: event.Task, final: final})
+}
+
+// MessageId returns the ID of the message whose task is handled with the context.
+func MessageId(ctx context.Context) string {
+	d, _ := ctx.Value(deliveryKey{}).(delivery)
+	return d.messageId
+}
+
+// isFinalAttempt reports whether a failure of the handled task sends it to the DLQ.
+func isFinalAttempt(ctx context.Context) bool {
+	d, _ := ctx.Value(deliveryKey{}).(delivery)
+	return d.final
+}
+
+// isProcessed reports whether the ledger already holds the message of the event.
+func isProcessed(ctx context.Context, event QueueEvent) (bool, error) {
+	if event.Id == "" {
+		return false, nil
+	}
+	db := contexthelper.GetDb(ctx)
+	if db == nil {
+		return false, errors.New("failed to get db")
+	}
+	return repository.NewProcessedMessageRepository(db).Exists(ctx, event.Id)
+}
+
+// markProcessed records the handled message in the ledger. Handlers pass their transaction
+// so the record is committed together with their changes, as the email handlers and
+// generateReport do; the consumer records every successfully handled message afterwards,
+// which is a no-op when already recorded. Tasks without such a transaction, like the data
+// export, are at-least-once: a crash before the consumer records the message runs the
+// task again on redelivery.
+func markProcessed(ctx context.Context, db repository.DBExecutor) error {
+	d, _ := ctx.Value(deliveryKey{}).(delivery)
+	if d.messageId == "" {
+		return nil
+	}
+	return repository.NewProcessedMessageRepository(db).Record(ctx, d.messageId, d.task)
+}
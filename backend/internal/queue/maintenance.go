@@ -0,0 +1,96 @@
+package queue
+
+import (
+	"backend/config"
+	"backend/internal/contexthelper"
+	"backend/internal/repository"
+	"backend/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaintenanceQueue runs housekeeping tasks, published by the scheduler.
+var MaintenanceQueue = newTaskQueue("maintenance", QueueSet{
+	Main:  "maintenance_tasks",
+	Retry: "maintenance_tasks_retry",
+	DLQ:   "maintenance_tasks_dlq",
+}, RetryPolicy{
+	MaxRetries: 1,
+	Delay:      time.Minute,
+})
+
+const (
+	// processedMessageRetention is how long handled message IDs are kept. Redeliveries
+	// happen within minutes, so a week leaves plenty of margin.
+	processedMessageRetention = 7 * 24 * time.Hour
+	scheduledJobRetention     = 7 * 24 * time.Hour
+)
+
+const defaultTokenRetentionDays = 30
+
+var (
+	PruneQueueHistoryTask       = Register(MaintenanceQueue, "prune_queue_history", pruneQueueHistory)
+	SweepConfirmationTokensTask = Register(MaintenanceQueue, "sweep_confirmation_tokens", sweepConfirmationTokens)
+)
+
+// ScheduleMaintenance adds the recurring housekeeping jobs to the scheduler.
+func ScheduleMaintenance(s *Scheduler, cfg *config.Config) error {
+	purgeInterval := cfg.AccountDeletion.PurgeIntervalMinutes
+	if purgeInterval <= 0 {
+		purgeInterval = defaultPurgeIntervalMinutes
+	}
+	return errors.Join(
+		AddRecurring(s, "purge_deleted_accounts", fmt.Sprintf("@every %dm", purgeInterval), PurgeDeletedAccountsTask, struct{}{}),
+		AddRecurring(s, "prune_queue_history", "@daily", PruneQueueHistoryTask, struct{}{}),
+		AddRecurring(s, "sweep_confirmation_tokens", "*/15 * * * *", SweepConfirmationTokensTask, struct{}{}),
+	)
+}
+
+// pruneQueueHistory removes old entries of the processed-message ledger and published
+// scheduled jobs.
+func pruneQueueHistory(ctx context.Context, _ struct{}) error {
+	db := contexthelper.GetDb(ctx)
+	if db == nil {
+		return errors.New("failed to get db")
+	}
+	messages, err := repository.NewProcessedMessageRepository(db).DeleteOlderThan(ctx, processedMessageRetention)
+	if err != nil {
+		return err
+	}
+	jobs, err := repository.NewScheduledJobRepository(db).DeletePublishedOlderThan(ctx, scheduledJobRetention)
+	if err != nil {
+		return err
+	}
+	logger.InfoCtx(ctx, "Pruned %d processed message(s) and %d scheduled job(s)", messages, jobs)
+	return nil
+}
+
+// sweepConfirmationTokens marks expired confirmation tokens as EXPIRED and removes the ones
+// that are no longer active after confirmation_tokens.retention_days.
+func sweepConfirmationTokens(ctx context.Context, _ struct{}) error {
+	db := contexthelper.GetDb(ctx)
+	if db == nil {
+		return errors.New("failed to get db")
+	}
+	cfg := contexthelper.GetConfig(ctx)
+	if cfg == nil {
+		return errors.New("failed to get config")
+	}
+	retentionDays := cfg.ConfirmationTokens.RetentionDays
+	if retentionDays <= 0 {
+		retentionDays = defaultTokenRetentionDays
+	}
+	tokenRepo := repository.NewConfirmationTokenRepository(db)
+	expired, err := tokenRepo.ExpireTokens(ctx)
+	if err != nil {
+		return err
+	}
+	deleted, err := tokenRepo.DeleteFinishedOlderThan(ctx, time.Duration(retentionDays)*24*time.Hour)
+	if err != nil {
+		return err
+	}
+	logger.InfoCtx(ctx, "Confirmation tokens swept: %d expired, %d deleted", expired, deleted)
+	return nil
+}
@@ -0,0 +1,239 @@
+package queue
+
+import (
+	"backend/config"
+	"backend/internal/metrics"
+	"backend/internal/repository"
+	"backend/pkg/logger"
+	"backend/pkg/rabbitmq"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// QueueSet names the main queue of a task group together with its retry and dead letter queues.
+type QueueSet struct {
+	Main  string
+	Retry string
+	DLQ   string
+}
+
+// MaxRetryDelay caps the delay of a retry, which also keeps the TTL of the retry queues
+// within the limit of RabbitMQ.
+const MaxRetryDelay = 24 * time.Hour
+
+// RetryPolicy controls how often and how late a failed task is redelivered.
+// The delay of the n-th retry is Delay * BackoffFactor^(n-1), at most MaxRetryDelay; a
+// factor of 1 or less keeps the delay constant.
+type RetryPolicy struct {
+	MaxRetries    int
+	Delay         time.Duration
+	BackoffFactor float64
+}
+
+// DelayFor returns the delay before the given retry attempt, starting at 1.
+func (p RetryPolicy) DelayFor(attempt int) time.Duration {
+	delay := min(p.Delay, MaxRetryDelay)
+	if p.BackoffFactor <= 1 {
+		return delay
+	}
+	for i := 1; i < attempt && delay < MaxRetryDelay; i++ {
+		delay = time.Duration(min(float64(delay)*p.BackoffFactor, float64(MaxRetryDelay)))
+	}
+	return delay.Round(time.Millisecond)
+}
+
+// TaskQueue is a group of tasks sharing a queue set and retry policy. Tasks are added
+// to it with Register and consumed with Consumer.Consume by Workers goroutines, each
+// holding up to Prefetch unacknowledged deliveries.
+type TaskQueue struct {
+	Name     string
+	Queues   QueueSet
+	Retry    RetryPolicy
+	Workers  int
+	Prefetch int
+	handlers map[string]func(ctx context.Context, data json.RawMessage) error
+}
+
+var taskQueues []*TaskQueue
+
+func newTaskQueue(name string, queues QueueSet, retry RetryPolicy) *TaskQueue {
+	q := &TaskQueue{
+		Name:     name,
+		Queues:   queues,
+		Retry:    retry,
+		Workers:  1,
+		Prefetch: 1,
+		handlers: map[string]func(ctx context.Context, data json.RawMessage) error{},
+	}
+	taskQueues = append(taskQueues, q)
+	return q
+}
+
+// Configure applies the queue settings of the config to the task queues. It must be
+// called on startup, before anything is published or consumed, by every process
+// declaring the queues so they all agree on the retry queue layout.
+func Configure(cfg config.QueuesConfig) {
+	EmailQueue.configure(cfg.Email)
+	ReportQueue.configure(cfg.Report)
+	MaintenanceQueue.configure(cfg.Maintenance)
+}
+
+func (q *TaskQueue) configure(cfg config.QueueConfig) {
+	if cfg.Workers > 0 {
+		q.Workers = cfg.Workers
+	}
+	if cfg.Prefetch > 0 {
+		q.Prefetch = cfg.Prefetch
+	}
+	if cfg.MaxRetries > 0 {
+		q.Retry.MaxRetries = cfg.MaxRetries
+	}
+	if cfg.RetryDelayMs > 0 {
+		q.Retry.Delay = time.Duration(cfg.RetryDelayMs) * time.Millisecond
+	}
+	if cfg.RetryBackoffFactor > 0 {
+		q.Retry.BackoffFactor = cfg.RetryBackoffFactor
+	}
+}
+
+// RetryQueue returns the name of the retry queue holding the given retry attempt.
+// Retry queues are named after their delay, so changing the policy declares new
+// queues instead of conflicting with the arguments of existing ones.
+func (q *TaskQueue) RetryQueue(attempt int) string {
+	return fmt.Sprintf("%s_%dms", q.Queues.Retry, q.Retry.DelayFor(attempt).Milliseconds())
+}
+
+// retryQueues returns the delay of every distinct retry queue keyed by its name.
+func (q *TaskQueue) retryQueues() map[string]time.Duration {
+	queues := map[string]time.Duration{}
+	for attempt := 1; attempt <= max(q.Retry.MaxRetries, 1); attempt++ {
+		queues[q.RetryQueue(attempt)] = q.Retry.DelayFor(attempt)
+	}
+	return queues
+}
+
+// TaskQueues returns all task queues of the application.
+func TaskQueues() []*TaskQueue {
+	return taskQueues
+}
+
+// GetTaskQueue returns the task queue with the given name.
+func GetTaskQueue(name string) (*TaskQueue, bool) {
+	for _, q := range taskQueues {
+		if q.Name == name {
+			return q, true
+		}
+	}
+	return nil, false
+}
+
+// Task is a typed handle of a registered task used to publish it.
+type Task[T any] struct {
+	name  string
+	queue *TaskQueue
+}
+
+// Register adds a handler for the named task to the queue and returns a handle for
+// publishing it. Registrations happen during package initialisation, so duplicates panic.
+func Register[T any](q *TaskQueue, name string, handler func(ctx context.Context, data T) error) Task[T] {
+	if _, exists := q.handlers[name]; exists {
+		panic(fmt.Sprintf("queue: task %s already registered on %s queue", name, q.Name))
+	}
+	q.handlers[name] = func(ctx context.Context, raw json.RawMessage) error {
+		var data T
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return permanent(fmt.Errorf("decode %s task data: %w", name, err))
+		}
+		return handler(ctx, data)
+	}
+	return Task[T]{name: name, queue: q}
+}
+
+// permanentError is a task failure that a retry cannot fix, e.g. data that does not
+// decode. The task is moved to the DLQ without retries.
+type permanentError struct {
+	err error
+}
+
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+func isPermanent(err error) bool {
+	var permanentErr *permanentError
+	return errors.As(err, &permanentErr)
+}
+
+func (t Task[T]) Name() string {
+	return t.name
+}
+
+// Publish enqueues the task with the given data on its queue.
+func (t Task[T]) Publish(ctx context.Context, rabbitConn *rabbitmq.Connection, data T) error {
+	return t.publish(ctx, rabbitConn, "", data)
+}
+
+// PublishDelayed schedules the task to be published at the given time. The job is stored
+// in db, which may be a transaction, and published by the scheduler of the consumer.
+func (t Task[T]) PublishDelayed(ctx context.Context, db repository.DBExecutor, at time.Time, data T) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	headers, err := json.Marshal(originHeaders(ctx))
+	if err != nil {
+		return err
+	}
+	_, err = repository.NewScheduledJobRepository(db).Create(ctx, t.queue.Name, t.name, jsonData, headers, time.Until(at))
+	return err
+}
+
+// publish enqueues the task; messageId identifies repeated publishing of the same job
+// (e.g. by two schedulers) and is generated when empty.
+func (t Task[T]) publish(ctx context.Context, rabbitConn *rabbitmq.Connection, messageId string, data T) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	event := QueueEvent{
+		Id:   messageId,
+		Task: t.name,
+		Data: json.RawMessage(jsonData),
+	}
+	return t.queue.publish(ctx, rabbitConn, event, originHeaders(ctx))
+}
+
+func (q *TaskQueue) handle(ctx context.Context, event QueueEvent) error {
+	handler, ok := q.handlers[event.Task]
+	if !ok {
+		logger.ErrorCtx(ctx, "❌ Unknown %s task: %s", q.Name, event.Task)
+		return fmt.Errorf("unknown %s task: %s", q.Name, event.Task)
+	}
+	return handler(ctx, event.Data)
+}
+
+func (q *TaskQueue) setup(ch *amqp.Channel) error {
+	return setupQueues(ch, q.Queues.Main, q.Queues.DLQ, q.retryQueues())
+}
+
+func (q *TaskQueue) publish(ctx context.Context, rabbitConn *rabbitmq.Connection, event QueueEvent, headers amqp.Table) error {
+	err := rabbitConn.DeclareOnce(q.Queues.Main, q.setup)
+	if err == nil {
+		err = publishJSON(ctx, rabbitConn, q.Queues.Main, event, amqp.Publishing{Headers: headers})
+	}
+	metrics.Published(q.Name, err)
+	return err
+}
@@ -0,0 +1,138 @@
+package queue
+
+import (
+	"backend/internal/contexthelper"
+	"context"
+	"database/sql/driver"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestRetryPolicy_DelayFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		attempt int
+		want    time.Duration
+	}{
+		{"attempt 0", RetryPolicy{Delay: 5 * time.Second, BackoffFactor: 2}, 0, 5 * time.Second},
+		{"first retry", RetryPolicy{Delay: 5 * time.Second, BackoffFactor: 2}, 1, 5 * time.Second},
+		{"third retry", RetryPolicy{Delay: 5 * time.Second, BackoffFactor: 2}, 3, 20 * time.Second},
+		{"fractional factor", RetryPolicy{Delay: 1 * time.Second, BackoffFactor: 1.5}, 4, 3375 * time.Millisecond},
+		{"rounded to milliseconds", RetryPolicy{Delay: 1001 * time.Microsecond, BackoffFactor: 1.5}, 2, 2 * time.Millisecond},
+		{"constant without factor", RetryPolicy{Delay: 5 * time.Second}, 5, 5 * time.Second},
+		{"constant with factor 1", RetryPolicy{Delay: 5 * time.Second, BackoffFactor: 1}, 5, 5 * time.Second},
+		{"capped", RetryPolicy{Delay: time.Hour, BackoffFactor: 2}, 10, MaxRetryDelay},
+		{"capped without overflow", RetryPolicy{Delay: time.Hour, BackoffFactor: 10}, 1000, MaxRetryDelay},
+		{"capped initial delay", RetryPolicy{Delay: 48 * time.Hour}, 1, MaxRetryDelay},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.DelayFor(tt.attempt); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTaskQueue_RetryQueues(t *testing.T) {
+	q := &TaskQueue{
+		Queues: QueueSet{Retry: "email_tasks_retry"},
+		Retry:  RetryPolicy{MaxRetries: 4, Delay: 5 * time.Second, BackoffFactor: 2},
+	}
+
+	for attempt, want := range map[int]string{
+		1: "email_tasks_retry_5000ms",
+		2: "email_tasks_retry_10000ms",
+		4: "email_tasks_retry_40000ms",
+	} {
+		if got := q.RetryQueue(attempt); got != want {
+			t.Errorf("attempt %d: expected %q, got %q", attempt, want, got)
+		}
+	}
+
+	want := map[string]time.Duration{
+		"email_tasks_retry_5000ms":  5 * time.Second,
+		"email_tasks_retry_10000ms": 10 * time.Second,
+		"email_tasks_retry_20000ms": 20 * time.Second,
+		"email_tasks_retry_40000ms": 40 * time.Second,
+	}
+	if queues := q.retryQueues(); !maps.Equal(queues, want) {
+		t.Errorf("expected retry queues %v, got %v", want, queues)
+	}
+
+	constant := &TaskQueue{
+		Queues: QueueSet{Retry: "report_tasks_retry"},
+		Retry:  RetryPolicy{MaxRetries: 3, Delay: 10 * time.Second},
+	}
+	if queues := constant.retryQueues(); len(queues) != 1 || queues["report_tasks_retry_10000ms"] != 10*time.Second {
+		t.Errorf("expected a single retry queue for a constant delay, got %v", queues)
+	}
+}
+
+// delaySeconds matches the run_at offset of a scheduled job, which is a second shorter
+// when the clock ticks between computing and storing it.
+type delaySeconds int64
+
+func (d delaySeconds) Match(v driver.Value) bool {
+	seconds, ok := v.(int64)
+	return ok && (seconds == int64(d) || seconds == int64(d)-1)
+}
+
+func TestTask_PublishDelayed(t *testing.T) {
+	tests := []struct {
+		name        string
+		ctx         func() context.Context
+		at          time.Duration
+		wantHeaders string
+		wantDelay   delaySeconds
+	}{
+		{
+			name: "origin headers",
+			ctx: func() context.Context {
+				ctx := contexthelper.SetRequestID(context.Background(), "req-1")
+				return contexthelper.SetUserId(ctx, 7)
+			},
+			at:          time.Hour,
+			wantHeaders: `{"x-request-id":"req-1","x-user-id":"7"}`,
+			wantDelay:   3600,
+		},
+		{
+			name:        "no origin",
+			ctx:         context.Background,
+			at:          90 * time.Second,
+			wantHeaders: `{}`,
+			wantDelay:   90,
+		},
+		{
+			name:        "past time runs at once",
+			ctx:         context.Background,
+			at:          -time.Minute,
+			wantHeaders: `{}`,
+			wantDelay:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create sqlmock: %v", err)
+			}
+			defer db.Close()
+			mock.ExpectExec("INSERT INTO scheduled_jobs").
+				WithArgs(MaintenanceQueue.Name, testTask.Name(), []byte(`"payload"`), []byte(tt.wantHeaders), tt.wantDelay).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+
+			if err := testTask.PublishDelayed(tt.ctx(), db, time.Now().Add(tt.at), "payload"); err != nil {
+				t.Fatalf("PublishDelayed() error = %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package queue
+
+import (
+	"backend/internal/contexthelper"
+	"backend/internal/email"
+	"backend/internal/models"
+	"backend/internal/report"
+	"backend/internal/repository"
+	"backend/pkg/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ReportQueue builds reports and data exports.
+var ReportQueue = newTaskQueue("report", QueueSet{
+	Main:  "report_tasks",
+	Retry: "report_tasks_retry",
+	DLQ:   "report_tasks_dlq",
+}, RetryPolicy{
+	MaxRetries: 5,
+	Delay:      10 * time.Second,
+})
+
+var GenerateReportTask = Register(ReportQueue, "generate_report", generateReport)
+
+type ReportTask struct {
+	ReportId uint `json:"report_id"`
+}
+
+func generateReport(ctx context.Context, data ReportTask) error {
+	if data.ReportId == 0 {
+		return errors.New("empty report id")
+	}
+	db := contexthelper.GetDb(ctx)
+	reportRepo := repository.NewReportRepository(db)
+
+	rep, err := reportRepo.GetById(ctx, data.ReportId)
+	if err != nil {
+		return err
+	}
+	started, err := reportRepo.MarkRunning(ctx, rep.Id)
+	if err != nil {
+		return err
+	}
+	if !started {
+		logger.InfoCtx(ctx, "Report %d is already generated", rep.Id)
+		return nil
+	}
+
+	path, err := writeReportFile(ctx, db, rep)
+	if err != nil {
+		if markErr := reportRepo.MarkFailed(ctx, rep.Id, err.Error()); markErr != nil {
+			logger.ErrorCtx(ctx, "Failed to mark report %d as failed: %v", rep.Id, markErr)
+		}
+		return err
+	}
+	if err := finishReport(ctx, db, rep.Id, path); err != nil {
+		return err
+	}
+	logger.InfoCtx(ctx, "📄 Report %d (%s) stored as %s", rep.Id, rep.Type, path)
+
+	// The report is already available via the API, so a failed notification must not
+	// trigger a retry of the whole task.
+	if err := sendReportReadyEmail(ctx, db, rep); err != nil {
+		logger.ErrorCtx(ctx, "Failed to send report ready email for report %d: %v", rep.Id, err)
+	}
+	return nil
+}
+
+// finishReport marks the report as done and records the message in the ledger in one
+// transaction, so a redelivery after a crash neither builds nor announces it again.
+func finishReport(ctx context.Context, db *sql.DB, id uint, path string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := repository.NewReportRepository(tx).MarkDone(ctx, id, path); err != nil {
+		return err
+	}
+	if err := markProcessed(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RemoveUserReports deletes the generated report files of the given user.
+func RemoveUserReports(ctx context.Context, userId uint) {
+	cfg := contexthelper.GetConfig(ctx)
+	pattern := filepath.Join(cfg.Reports.StorageDir, fmt.Sprintf("report_%d_*", userId))
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to list reports of user %d: %v", userId, err)
+		return
+	}
+	for _, f := range files {
+		if err := os.Remove(f); err != nil {
+			logger.ErrorCtx(ctx, "Failed to remove report %s: %v", f, err)
+		}
+	}
+}
+
+func writeReportFile(ctx context.Context, db repository.DBExecutor, rep models.Report) (string, error) {
+	def, ok := report.Get(rep.Type)
+	if !ok {
+		return "", fmt.Errorf("unknown report type: %s", rep.Type)
+	}
+	var params report.Params
+	if err := json.Unmarshal([]byte(rep.Params), &params); err != nil {
+		return "", err
+	}
+
+	cfg := contexthelper.GetConfig(ctx)
+	if err := os.MkdirAll(cfg.Reports.StorageDir, 0750); err != nil {
+		return "", err
+	}
+	path := filepath.Join(cfg.Reports.StorageDir, fmt.Sprintf("report_%d_%d.%s", rep.UserId, rep.Id, rep.Format))
+	tmpPath := path + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmpPath)
+
+	rw, err := report.NewWriter(rep.Format, f)
+	if err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := def.Write(ctx, db, params, rw); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := rw.Close(); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	return path, os.Rename(tmpPath, path)
+}
+
+func sendReportReadyEmail(ctx context.Context, db repository.DBExecutor, rep models.Report) error {
+	user, err := repository.NewUserRepository(db).GetById(ctx, rep.UserId)
+	if err != nil {
+		return err
+	}
+	sender := email.GetEmailSender(ctx)
+	if sender == nil {
+		return errors.New("failed to get email sender")
+	}
+	cfg := contexthelper.GetConfig(ctx)
+	link := fmt.Sprintf("%s/api/reports/%d/file", cfg.Frontend.BaseURL, rep.Id)
+	err = sender.SendReportReadyEmail(ctx, user.Email, user.Name, getUserLangCode(ctx, db, user.Id), rep.Type, link)
+	if err != nil {
+		return err
+	}
+	logger.InfoCtx(ctx, "Report ready email sent to %s", logger.Email(user.Email))
+	return nil
+}
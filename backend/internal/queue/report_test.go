@@ -0,0 +1,57 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestFinishReport(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    func(m sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "done and recorded together",
+			mock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("UPDATE reports SET status").WithArgs("done", "/reports/report_1.csv", 5).WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectExec("INSERT IGNORE INTO processed_messages").WithArgs("msg-1", GenerateReportTask.Name()).WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+		},
+		{
+			name: "ledger failure rolls back",
+			mock: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("UPDATE reports SET status").WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectExec("INSERT IGNORE INTO processed_messages").WillReturnError(errors.New("connection lost"))
+				m.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create sqlmock: %v", err)
+			}
+			defer db.Close()
+			tt.mock(mock)
+
+			ctx := withDelivery(context.Background(), QueueEvent{Id: "msg-1", Task: GenerateReportTask.Name()}, false)
+			err = finishReport(ctx, db, 5, "/reports/report_1.csv")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("finishReport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
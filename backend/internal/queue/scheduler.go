@@ -0,0 +1,257 @@
+package queue
+
+import (
+	"backend/config"
+	"backend/internal/contexthelper"
+	"backend/internal/repository"
+	"backend/pkg/logger"
+	"backend/pkg/rabbitmq"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// schedulerLockPrefix is combined with the database name, as MySQL locks are server wide.
+	schedulerLockPrefix = "scheduler:"
+
+	leaderRetryInterval        = 15 * time.Second
+	leaderCheckInterval        = 15 * time.Second
+	defaultSchedulerPollPeriod = 5 * time.Second
+	delayedJobBatchSize        = 100
+)
+
+// Scheduler publishes recurring jobs on their cron schedule and delayed jobs stored by
+// Task.PublishDelayed once they are due. Jobs only publish tasks, the work is done by
+// the consumers of their queues. Replicas elect a leader with a database lock and only
+// the leader publishes, so every job fires once.
+type Scheduler struct {
+	pollInterval time.Duration
+	overrides    map[string]string
+	jobs         []*recurringJob
+}
+
+type recurringJob struct {
+	name     string
+	schedule cron.Schedule
+	publish  func(ctx context.Context, rabbitConn *rabbitmq.Connection, messageId string) error
+	next     time.Time
+}
+
+func NewScheduler(cfg config.SchedulerConfig) *Scheduler {
+	pollInterval := time.Duration(cfg.PollIntervalSeconds) * time.Second
+	if pollInterval <= 0 {
+		pollInterval = defaultSchedulerPollPeriod
+	}
+	return &Scheduler{
+		pollInterval: pollInterval,
+		overrides:    cfg.Jobs,
+	}
+}
+
+// AddRecurring publishes the task with data on the cron schedule spec (standard five
+// fields or descriptors like "@daily" and "@every 30m"). The spec can be overridden by
+// the job name in scheduler.jobs of the config.
+func AddRecurring[T any](s *Scheduler, name, spec string, task Task[T], data T) error {
+	if override, ok := s.overrides[name]; ok && override != "" {
+		spec = override
+	}
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return fmt.Errorf("invalid schedule %q of job %s: %w", spec, name, err)
+	}
+	s.jobs = append(s.jobs, &recurringJob{
+		name:     name,
+		schedule: schedule,
+		publish: func(ctx context.Context, rabbitConn *rabbitmq.Connection, messageId string) error {
+			return task.publish(ctx, rabbitConn, messageId, data)
+		},
+	})
+	logger.Info("⏰ Job %s scheduled: %s", name, spec)
+	return nil
+}
+
+// Run competes for the leadership and, while leading, publishes the jobs until the
+// context is cancelled.
+func (s *Scheduler) Run(ctx context.Context) error {
+	db := contexthelper.GetDb(ctx)
+	rabbitConn := contexthelper.GetRabbitConn(ctx)
+	if db == nil || rabbitConn == nil {
+		return errors.New("failed to get services")
+	}
+	for {
+		conn, err := acquireLeadership(ctx, db)
+		if err != nil && ctx.Err() == nil {
+			logger.ErrorCtx(ctx, "Scheduler leader election failed: %v", err)
+		}
+		if conn != nil {
+			logger.InfoCtx(ctx, "👑 Scheduler leadership acquired")
+			s.lead(ctx, conn, db, rabbitConn)
+			releaseLeadership(ctx, conn)
+			logger.InfoCtx(ctx, "Scheduler leadership released")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(leaderRetryInterval):
+		}
+	}
+}
+
+func (s *Scheduler) lead(ctx context.Context, conn *sql.Conn, db *sql.DB, rabbitConn *rabbitmq.Connection) {
+	now := time.Now()
+	for _, job := range s.jobs {
+		job.next = job.schedule.Next(now)
+	}
+	poll := time.NewTicker(s.pollInterval)
+	defer poll.Stop()
+	check := time.NewTicker(leaderCheckInterval)
+	defer check.Stop()
+
+	for {
+		timer := time.NewTimer(time.Until(s.nextRun()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.publishRecurring(ctx, rabbitConn, time.Now())
+		case <-poll.C:
+			timer.Stop()
+			s.publishDelayed(ctx, db, func(ctx context.Context, q *TaskQueue, event QueueEvent, headers amqp.Table) error {
+				return q.publish(ctx, rabbitConn, event, headers)
+			})
+		case <-check.C:
+			timer.Stop()
+			if !isLeader(ctx, conn) {
+				logger.WarnCtx(ctx, "Scheduler leadership lost")
+				return
+			}
+		}
+	}
+}
+
+func (s *Scheduler) nextRun() time.Time {
+	next := time.Now().Add(24 * time.Hour)
+	for _, job := range s.jobs {
+		if job.next.Before(next) {
+			next = job.next
+		}
+	}
+	return next
+}
+
+// publishRecurring publishes the jobs due at now. The message ID is derived from the
+// scheduled time, so a job fired twice around a leader change is handled once.
+func (s *Scheduler) publishRecurring(ctx context.Context, rabbitConn *rabbitmq.Connection, now time.Time) {
+	for _, job := range s.jobs {
+		if job.next.After(now) {
+			continue
+		}
+		messageId := fmt.Sprintf("cron:%s:%d", job.name, job.next.Unix())
+		jobCtx := contexthelper.SetRequestID(ctx, messageId)
+		if err := job.publish(jobCtx, rabbitConn, messageId); err != nil {
+			logger.ErrorCtx(jobCtx, "Failed to publish job %s: %v", job.name, err)
+		} else {
+			logger.InfoCtx(jobCtx, "⏰ Job %s published", job.name)
+		}
+		job.next = job.schedule.Next(now)
+	}
+}
+
+// publishTaskFunc publishes a task event on its queue.
+type publishTaskFunc func(ctx context.Context, q *TaskQueue, event QueueEvent, headers amqp.Table) error
+
+// publishDelayed publishes the due delayed jobs. Jobs of queues this binary does not
+// know yet (e.g. during a rolling deploy) are not selected and are left for a newer
+// replica.
+func (s *Scheduler) publishDelayed(ctx context.Context, db repository.DBExecutor, publish publishTaskFunc) {
+	repo := repository.NewScheduledJobRepository(db)
+	var queues []string
+	for _, q := range TaskQueues() {
+		queues = append(queues, q.Name)
+	}
+	for {
+		jobs, err := repo.GetDue(ctx, queues, delayedJobBatchSize)
+		if err != nil {
+			logger.ErrorCtx(ctx, "Failed to get due jobs: %v", err)
+			return
+		}
+		published := 0
+		for _, job := range jobs {
+			q, ok := GetTaskQueue(job.Queue)
+			if !ok {
+				// Not selected by GetDue, kept for safety
+				logger.WarnCtx(ctx, "Scheduled job %d has unknown queue %s", job.Id, job.Queue)
+				continue
+			}
+			headers := amqp.Table{}
+			if err := json.Unmarshal([]byte(job.Headers), &headers); err != nil {
+				logger.ErrorCtx(ctx, "Invalid headers of scheduled job %d: %v", job.Id, err)
+			}
+			event := QueueEvent{
+				Id:   fmt.Sprintf("scheduled:%d", job.Id),
+				Task: job.Task,
+				Data: json.RawMessage(job.Data),
+			}
+			// A crash before MarkPublished publishes the job again with the same message ID
+			if err := publish(ctx, q, event, headers); err != nil {
+				logger.ErrorCtx(ctx, "Failed to publish scheduled job %d: %v", job.Id, err)
+				return
+			}
+			if err := repo.MarkPublished(ctx, job.Id); err != nil {
+				logger.ErrorCtx(ctx, "Failed to mark scheduled job %d as published: %v", job.Id, err)
+				return
+			}
+			published++
+		}
+		if published > 0 {
+			logger.InfoCtx(ctx, "⏰ Published %d scheduled job(s)", published)
+		}
+		if len(jobs) < delayedJobBatchSize {
+			return
+		}
+	}
+}
+
+// acquireLeadership tries to take the scheduler lock on a dedicated connection. The lock
+// is held as long as the connection lives; nil is returned when another replica leads.
+func acquireLeadership(ctx context.Context, db *sql.DB) (*sql.Conn, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var acquired sql.NullInt64
+	err = conn.QueryRowContext(ctx, `SELECT GET_LOCK(CONCAT(?, DATABASE()), 0)`, schedulerLockPrefix).Scan(&acquired)
+	if err != nil || acquired.Int64 != 1 {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func isLeader(ctx context.Context, conn *sql.Conn) bool {
+	var leader sql.NullBool
+	err := conn.QueryRowContext(ctx, `SELECT IS_USED_LOCK(CONCAT(?, DATABASE())) = CONNECTION_ID()`, schedulerLockPrefix).Scan(&leader)
+	return err == nil && leader.Bool
+}
+
+// releaseLeadership releases the lock before returning the connection to the pool, where
+// it would otherwise keep the lock.
+func releaseLeadership(ctx context.Context, conn *sql.Conn) {
+	releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if _, err := conn.ExecContext(releaseCtx, `DO RELEASE_LOCK(CONCAT(?, DATABASE()))`, schedulerLockPrefix); err != nil {
+		logger.ErrorCtx(ctx, "Failed to release scheduler lock: %v", err)
+		// Drop the connection instead, which releases the lock on the server
+		conn.Raw(func(any) error { return driver.ErrBadConn })
+	}
+	conn.Close()
+}
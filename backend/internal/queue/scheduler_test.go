@@ -0,0 +1,137 @@
+package queue
+
+import (
+	"backend/config"
+	"backend/pkg/rabbitmq"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/robfig/cron/v3"
+)
+
+var testTask = Task[string]{name: "test_task", queue: MaintenanceQueue}
+
+func TestAddRecurring(t *testing.T) {
+	start := time.Date(2026, 10, 19, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		spec      string
+		overrides map[string]string
+		wantNext  time.Time
+		wantErr   bool
+	}{
+		{name: "spec", spec: "@daily", wantNext: time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)},
+		{name: "override", spec: "@daily", overrides: map[string]string{"job": "0 * * * *"}, wantNext: time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC)},
+		{name: "empty override", spec: "@daily", overrides: map[string]string{"job": ""}, wantNext: time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)},
+		{name: "bad spec", spec: "every day", wantErr: true},
+		{name: "bad override", spec: "@daily", overrides: map[string]string{"job": "61 * * * *"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScheduler(config.SchedulerConfig{Jobs: tt.overrides})
+
+			err := AddRecurring(s, "job", tt.spec, testTask, "data")
+
+			if tt.wantErr {
+				if err == nil || len(s.jobs) != 0 {
+					t.Fatalf("expected an error and no job, got %v and %d job(s)", err, len(s.jobs))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AddRecurring failed: %v", err)
+			}
+			if got := s.jobs[0].schedule.Next(start); !got.Equal(tt.wantNext) {
+				t.Errorf("expected next run at %v, got %v", tt.wantNext, got)
+			}
+		})
+	}
+}
+
+func TestPublishRecurring_MessageId(t *testing.T) {
+	schedule, _ := cron.ParseStandard("@hourly")
+	due := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	var published []string
+	publish := func(_ context.Context, _ *rabbitmq.Connection, messageId string) error {
+		published = append(published, messageId)
+		return nil
+	}
+	s := &Scheduler{jobs: []*recurringJob{
+		{name: "due", schedule: schedule, next: due, publish: publish},
+		{name: "later", schedule: schedule, next: due.Add(time.Hour), publish: publish},
+	}}
+
+	s.publishRecurring(context.Background(), nil, due.Add(time.Second))
+
+	if want := fmt.Sprintf("cron:due:%d", due.Unix()); len(published) != 1 || published[0] != want {
+		t.Fatalf("expected only the due job with a message ID of its scheduled time, got %v", published)
+	}
+	if want := due.Add(time.Hour); !s.jobs[0].next.Equal(want) {
+		t.Errorf("expected the next run at %v, got %v", want, s.jobs[0].next)
+	}
+}
+
+func expectDueJobs(mock sqlmock.Sqlmock, queue string, firstId, count int) {
+	args := []driver.Value{}
+	for _, q := range TaskQueues() {
+		args = append(args, q.Name)
+	}
+	args = append(args, delayedJobBatchSize)
+	rows := sqlmock.NewRows([]string{"id", "queue", "task", "data", "headers", "run_at", "published_at", "created_at"})
+	for id := firstId; id < firstId+count; id++ {
+		rows.AddRow(id, queue, "test_task", `"data"`, `{}`, time.Now(), nil, time.Now())
+	}
+	mock.ExpectQuery("SELECT .* FROM scheduled_jobs WHERE published_at IS NULL AND run_at <= NOW\\(\\) AND queue IN").WithArgs(args...).WillReturnRows(rows)
+}
+
+// Jobs of unknown queues are never published, so they must not fill the batches and hide
+// the due jobs behind them.
+func TestPublishDelayed_UnknownQueuesDoNotStarveBatches(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	expectDueJobs(mock, MaintenanceQueue.Name, 1, delayedJobBatchSize)
+	for id := 1; id <= delayedJobBatchSize; id++ {
+		mock.ExpectExec("UPDATE scheduled_jobs SET published_at").WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	expectDueJobs(mock, MaintenanceQueue.Name, delayedJobBatchSize+1, 1)
+	mock.ExpectExec("UPDATE scheduled_jobs SET published_at").WithArgs(delayedJobBatchSize + 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	var published []string
+	NewScheduler(config.SchedulerConfig{}).publishDelayed(context.Background(), db, func(_ context.Context, q *TaskQueue, event QueueEvent, _ amqp.Table) error {
+		published = append(published, event.Id)
+		return nil
+	})
+
+	if len(published) != delayedJobBatchSize+1 || published[0] != "scheduled:1" {
+		t.Errorf("expected %d published jobs, got %d", delayedJobBatchSize+1, len(published))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPublishDelayed_StopsOnPublishError(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	expectDueJobs(mock, MaintenanceQueue.Name, 1, 2)
+
+	calls := 0
+	NewScheduler(config.SchedulerConfig{}).publishDelayed(context.Background(), db, func(context.Context, *TaskQueue, QueueEvent, amqp.Table) error {
+		calls++
+		return errors.New("connection closed")
+	})
+
+	if calls != 1 {
+		t.Errorf("expected publishing to stop after the first error, got %d call(s)", calls)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
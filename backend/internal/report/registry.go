@@ -0,0 +1,58 @@
+package report
+
+import (
+	"backend/internal/repository"
+	"context"
+	"sort"
+	"time"
+)
+
+// Params are the user supplied options of a report, stored as JSON with the report.
+type Params struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// EmitFunc receives a single report row; it must be called in column order.
+type EmitFunc func(row []string) error
+
+// Definition describes a report type: its columns and how its rows are produced.
+type Definition struct {
+	Name     string
+	Columns  []string
+	Generate func(ctx context.Context, db repository.DBExecutor, params Params, emit EmitFunc) error
+}
+
+var registry = map[string]Definition{}
+
+// Register adds a report type to the registry. It panics on duplicate names, as
+// registrations happen in init functions.
+func Register(def Definition) {
+	if _, exists := registry[def.Name]; exists {
+		panic("report: duplicate report type " + def.Name)
+	}
+	registry[def.Name] = def
+}
+
+func Get(name string) (Definition, bool) {
+	def, ok := registry[name]
+	return def, ok
+}
+
+// Names returns the registered report types in alphabetical order.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Write streams the header and all rows of the report into rw.
+func (d Definition) Write(ctx context.Context, db repository.DBExecutor, params Params, rw RowWriter) error {
+	if err := rw.WriteRow(d.Columns); err != nil {
+		return err
+	}
+	return d.Generate(ctx, db, params, rw.WriteRow)
+}
@@ -0,0 +1,58 @@
+package report
+
+import (
+	"backend/internal/models"
+	"backend/internal/repository"
+	"context"
+	"strconv"
+	"time"
+)
+
+const (
+	TypeUserRegistrations = "user_registrations"
+	TypeUserSessions      = "user_sessions"
+
+	dateTimeFormat = "2006-01-02 15:04:05"
+)
+
+func init() {
+	Register(Definition{
+		Name:    TypeUserRegistrations,
+		Columns: []string{"id", "name", "email", "registered_at", "confirmed_at"},
+		Generate: func(ctx context.Context, db repository.DBExecutor, params Params, emit EmitFunc) error {
+			return repository.NewUserRepository(db).StreamRegisteredBetween(ctx, params.From, params.To, func(u models.User) error {
+				return emit([]string{
+					strconv.FormatUint(uint64(u.Id), 10),
+					u.Name,
+					u.Email,
+					formatTime(u.RegisteredAt),
+					formatTime(u.ConfirmedAt),
+				})
+			})
+		},
+	})
+	Register(Definition{
+		Name:    TypeUserSessions,
+		Columns: []string{"id", "user_id", "created_at", "expires_at", "refreshed_at", "revoked_at", "user_agent"},
+		Generate: func(ctx context.Context, db repository.DBExecutor, params Params, emit EmitFunc) error {
+			return repository.NewUserSessionsRepository(db).StreamCreatedBetween(ctx, params.From, params.To, func(s models.UserSessions) error {
+				return emit([]string{
+					strconv.FormatUint(uint64(s.Id), 10),
+					strconv.FormatUint(uint64(s.UserId), 10),
+					formatTime(s.CreatedAt),
+					formatTime(s.ExpiresAt),
+					formatTime(s.RefreshedAt),
+					formatTime(s.RevokedAt),
+					s.UserAgent,
+				})
+			})
+		},
+	})
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(dateTimeFormat)
+}
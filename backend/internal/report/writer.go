@@ -0,0 +1,45 @@
+package report
+
+import (
+	"backend/internal/models"
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// RowWriter streams report rows into an output format.
+type RowWriter interface {
+	WriteRow(row []string) error
+	// Close flushes buffered data; it does not close the underlying io.Writer.
+	Close() error
+}
+
+// NewWriter returns a RowWriter for the given format.
+func NewWriter(format string, w io.Writer) (RowWriter, error) {
+	switch format {
+	case models.ReportFormatCsv:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case models.ReportFormatXlsx:
+		return newXlsxWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported report format: %s", format)
+	}
+}
+
+// IsSupportedFormat reports whether NewWriter accepts the format.
+func IsSupportedFormat(format string) bool {
+	return format == models.ReportFormatCsv || format == models.ReportFormatXlsx
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) WriteRow(row []string) error {
+	return c.w.Write(row)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -7,6 +7,7 @@ import (
 
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -18,27 +19,36 @@ const (
 
 	getActiveNewTokenSql = `SELECT ` + confirmationTokenColumns + ` FROM ` + ConfirmationTokenTable + ` WHERE token = ? AND status = "` + models.ConfirmationTokenStatusNew + `" AND expires_at > NOW()`
 
-	// Check for active tokens with status 'new' or 'processing'
-	activeStatusWhereCondition = `status IN ("` + models.ConfirmationTokenStatusNew + `","` + models.ConfirmationTokenStatusProcessing + `")`
+	// Check for active tokens with status 'new' or 'processing' that have not expired yet
+	activeStatusWhereCondition = `status IN ("` + models.ConfirmationTokenStatusNew + `","` + models.ConfirmationTokenStatusProcessing + `") AND expires_at > NOW()`
 	checkRegisterEmailSql      = `SELECT 1 FROM ` + ConfirmationTokenTable + ` WHERE type ='` + models.ConfirmationTokenTypeRegister + `' AND JSON_UNQUOTE(JSON_EXTRACT(payload, '$.email')) = ? AND ` + activeStatusWhereCondition
 	checkNewEmailSql           = `SELECT 1 FROM ` + ConfirmationTokenTable + ` WHERE type ='` + models.ConfirmationTokenTypeEmailChange + `' AND JSON_UNQUOTE(JSON_EXTRACT(payload, '$.new_email')) = ? AND ` + activeStatusWhereCondition
+	userTokensWhereCondition   = `(user_id = ? OR (user_id = 0 AND type = '` + models.ConfirmationTokenTypeRegister + `' AND JSON_UNQUOTE(JSON_EXTRACT(payload, '$.email')) = ?))`
+	deliverableStatuses        = `status IN ("` + models.ConfirmationTokenStatusNew + `","` + models.ConfirmationTokenStatusProcessing + `","` + models.ConfirmationTokenStatusFailed + `")`
+	resendableTokenSql         = `SELECT ` + confirmationTokenColumns + ` FROM ` + ConfirmationTokenTable + ` WHERE type = ? AND ` + deliverableStatuses + ` AND expires_at > NOW()`
 	createTokenSqlPattern      = `INSERT INTO ` + ConfirmationTokenTable + ` (token, user_id, type, payload, status, expires_at, status_changed_at) VALUES (?, ?, '%s', ?, "` + models.ConfirmationTokenStatusNew + `", DATE_ADD(NOW(), INTERVAL %d DAY), NOW())`
 )
 
+// ErrConfirmationTokenConsumed is returned by ClaimToken for a token that was already used.
+var ErrConfirmationTokenConsumed = errors.New("confirmation token already consumed")
+
+// ConfirmationTokenRepository stores only the SHA-256 hash of a token, like the sessions do.
+// Methods take and return the plain token, which exists only in the emailed links and the
+// tasks sending them; Token of the returned models is the hash.
 type ConfirmationTokenRepository struct {
 	db DBExecutor
 }
 
 func NewConfirmationTokenRepository(db DBExecutor) *ConfirmationTokenRepository {
-	return &ConfirmationTokenRepository{db: db}
+	return &ConfirmationTokenRepository{db: traced(db)}
 }
 
 func (r *ConfirmationTokenRepository) GetActiveNewToken(ctx context.Context, token string) (models.ConfirmationToken, error) {
 	var ct models.ConfirmationToken
 
-	err := r.db.QueryRowContext(ctx, getActiveNewTokenSql, token).Scan(&ct.Id, &ct.Token, &ct.UserId, &ct.Type, &ct.Payload, &ct.Status, &ct.ExpiresAt, &ct.StatusChangedAt, &ct.CreatedAt)
+	err := r.db.QueryRowContext(ctx, getActiveNewTokenSql, hashToken(token)).Scan(&ct.Id, &ct.Token, &ct.UserId, &ct.Type, &ct.Payload, &ct.Status, &ct.ExpiresAt, &ct.StatusChangedAt, &ct.CreatedAt)
 	if err != nil || ct.Id == 0 {
-		return models.ConfirmationToken{}, errors.Wrap(err, "Failed to retrieve active confirmation token: "+token)
+		return models.ConfirmationToken{}, errors.Wrap(err, "Failed to retrieve active confirmation token")
 	}
 	return ct, nil
 }
@@ -77,22 +87,196 @@ func (r *ConfirmationTokenRepository) CreatePasswordChangeToken(ctx context.Cont
 	return r.createToken(ctx, userId, models.ConfirmationTokenTypePasswordChange, []byte("{}"), days)
 }
 
+func (r *ConfirmationTokenRepository) CreateAccountDeletionToken(ctx context.Context, userId uint, payload []byte, days int) (string, error) {
+	return r.createToken(ctx, userId, models.ConfirmationTokenTypeAccountDeletion, payload, days)
+}
+
+// GetByUser returns the tokens of the user, including the register token. Register tokens
+// confirmed before AssignUser was introduced have no user id and are matched by email.
+func (r *ConfirmationTokenRepository) GetByUser(ctx context.Context, userId uint, email string) ([]models.ConfirmationToken, error) {
+	list := []models.ConfirmationToken{}
+	rows, err := r.db.QueryContext(ctx, `SELECT `+confirmationTokenColumns+` FROM `+ConfirmationTokenTable+` WHERE `+userTokensWhereCondition+` ORDER BY created_at`, userId, email)
+	if err != nil {
+		return list, errors.Wrap(err, "Failed to retrieve confirmation tokens")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ct models.ConfirmationToken
+		if err := rows.Scan(&ct.Id, &ct.Token, &ct.UserId, &ct.Type, &ct.Payload, &ct.Status, &ct.ExpiresAt, &ct.StatusChangedAt, &ct.CreatedAt); err != nil {
+			return list, err
+		}
+		list = append(list, ct)
+	}
+	return list, rows.Err()
+}
+
+// DeleteByUser deletes the tokens of the user matched like GetByUser.
+func (r *ConfirmationTokenRepository) DeleteByUser(ctx context.Context, userId uint, email string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM `+ConfirmationTokenTable+` WHERE `+userTokensWhereCondition, userId, email)
+	if err != nil {
+		return errors.Wrap(err, "Failed to delete confirmation tokens")
+	}
+	return nil
+}
+
+// AssignUser links the register token to the user created from it, so the token, which
+// holds the user's data, is exported and purged with the account.
+func (r *ConfirmationTokenRepository) AssignUser(ctx context.Context, tokenId uint, userId uint) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE `+ConfirmationTokenTable+` SET user_id = ? WHERE id = ?`, userId, tokenId)
+	if err != nil {
+		return errors.Wrap(err, "Failed to assign confirmation token to user")
+	}
+	return nil
+}
+
 func (r *ConfirmationTokenRepository) ConsumeToken(ctx context.Context, token string) error {
 	return r.updateStatus(ctx, token, models.ConfirmationTokenStatusConsumed)
 }
 
+// ClaimToken locks the token until the end of the transaction the repository was created
+// with and returns it when it can be confirmed. The action of the token and ConsumeToken
+// must follow in the same transaction: a concurrent claim waits for the lock and then gets
+// ErrConfirmationTokenConsumed. It returns sql.ErrNoRows for unknown, expired, cancelled
+// and failed tokens.
+func (r *ConfirmationTokenRepository) ClaimToken(ctx context.Context, token string) (models.ConfirmationToken, error) {
+	var ct models.ConfirmationToken
+	err := r.db.QueryRowContext(ctx, `SELECT `+confirmationTokenColumns+` FROM `+ConfirmationTokenTable+` WHERE token = ? AND expires_at > NOW() FOR UPDATE`, hashToken(token)).
+		Scan(&ct.Id, &ct.Token, &ct.UserId, &ct.Type, &ct.Payload, &ct.Status, &ct.ExpiresAt, &ct.StatusChangedAt, &ct.CreatedAt)
+	if err != nil {
+		return models.ConfirmationToken{}, err
+	}
+	switch ct.Status {
+	case models.ConfirmationTokenStatusNew, models.ConfirmationTokenStatusProcessing:
+		// PROCESSING: the email is out, but its delivery was not recorded yet
+		return ct, nil
+	case models.ConfirmationTokenStatusConsumed:
+		return models.ConfirmationToken{}, ErrConfirmationTokenConsumed
+	default:
+		return models.ConfirmationToken{}, sql.ErrNoRows
+	}
+}
+
 func (r *ConfirmationTokenRepository) GetActiveNewTokenWithType(ctx context.Context, token string, tokenType string) (models.ConfirmationToken, error) {
 	var ct models.ConfirmationToken
-	err := r.db.QueryRowContext(ctx, getActiveNewTokenSql+` AND type = ?`, token, tokenType).Scan(&ct.Id, &ct.Token, &ct.UserId, &ct.Type, &ct.Payload, &ct.Status, &ct.ExpiresAt, &ct.StatusChangedAt, &ct.CreatedAt)
+	err := r.db.QueryRowContext(ctx, getActiveNewTokenSql+` AND type = ?`, hashToken(token), tokenType).Scan(&ct.Id, &ct.Token, &ct.UserId, &ct.Type, &ct.Payload, &ct.Status, &ct.ExpiresAt, &ct.StatusChangedAt, &ct.CreatedAt)
+	if err != nil {
+		return models.ConfirmationToken{}, err
+	}
+	return ct, nil
+}
+
+// StartDelivery marks the token PROCESSING while its email is being sent and returns it.
+// It returns sql.ErrNoRows when the token was meanwhile consumed, cancelled or has expired,
+// so there is nothing left to deliver.
+func (r *ConfirmationTokenRepository) StartDelivery(ctx context.Context, token string, tokenType string) (models.ConfirmationToken, error) {
+	var ct models.ConfirmationToken
+	err := r.db.QueryRowContext(ctx, `SELECT `+confirmationTokenColumns+` FROM `+ConfirmationTokenTable+` WHERE token = ? AND type = ? AND `+deliverableStatuses+` AND expires_at > NOW()`, hashToken(token), tokenType).
+		Scan(&ct.Id, &ct.Token, &ct.UserId, &ct.Type, &ct.Payload, &ct.Status, &ct.ExpiresAt, &ct.StatusChangedAt, &ct.CreatedAt)
+	if err != nil {
+		return models.ConfirmationToken{}, err
+	}
+	if ct.Status == models.ConfirmationTokenStatusProcessing {
+		// A previous attempt did not finish
+		return ct, nil
+	}
+	// Only move on from the status read above, so a concurrent consumption is not overwritten
+	result, err := r.db.ExecContext(ctx, `UPDATE `+ConfirmationTokenTable+` SET status = ?, status_changed_at = NOW() WHERE id = ? AND status = ?`,
+		models.ConfirmationTokenStatusProcessing, ct.Id, ct.Status)
+	if err != nil {
+		return models.ConfirmationToken{}, errors.Wrap(err, "Failed to start confirmation token delivery")
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return models.ConfirmationToken{}, sql.ErrNoRows
+	}
+	ct.Status = models.ConfirmationTokenStatusProcessing
+	return ct, nil
+}
+
+// FinishDelivery returns a delivered token to NEW, where it waits for the confirmation.
+func (r *ConfirmationTokenRepository) FinishDelivery(ctx context.Context, token string) error {
+	return r.updateDeliveryStatus(ctx, token, models.ConfirmationTokenStatusNew)
+}
+
+// FailDelivery marks a token whose email could not be delivered as FAILED. Failed tokens
+// no longer block a new registration or email change with the same address.
+func (r *ConfirmationTokenRepository) FailDelivery(ctx context.Context, token string) error {
+	return r.updateDeliveryStatus(ctx, token, models.ConfirmationTokenStatusFailed)
+}
+
+func (r *ConfirmationTokenRepository) updateDeliveryStatus(ctx context.Context, token string, status string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE `+ConfirmationTokenTable+` SET status = ?, status_changed_at = NOW() WHERE token = ? AND status = ?`,
+		status, hashToken(token), models.ConfirmationTokenStatusProcessing)
+	if err != nil {
+		return errors.Wrap(err, "Failed to update confirmation token delivery status")
+	}
+	return nil
+}
+
+// GetResendableRegisterToken returns the latest register token of email whose email can be
+// sent again, or sql.ErrNoRows when there is none.
+func (r *ConfirmationTokenRepository) GetResendableRegisterToken(ctx context.Context, email string) (models.ConfirmationToken, error) {
+	return r.getResendableToken(ctx, ` AND JSON_UNQUOTE(JSON_EXTRACT(payload, '$.email')) = ?`, models.ConfirmationTokenTypeRegister, email)
+}
+
+// GetResendableEmailChangeToken returns the latest email change token of the user whose email
+// can be sent again, or sql.ErrNoRows when there is none.
+func (r *ConfirmationTokenRepository) GetResendableEmailChangeToken(ctx context.Context, userId uint) (models.ConfirmationToken, error) {
+	return r.getResendableToken(ctx, ` AND user_id = ?`, models.ConfirmationTokenTypeEmailChange, userId)
+}
+
+// MarkResent counts a resend of the token and returns a new token replacing it, as only
+// the hash of the current one is stored. It returns sql.ErrNoRows when the token was resent
+// max times already, its last email is more recent than cooldown or its status changed
+// meanwhile.
+func (r *ConfirmationTokenRepository) MarkResent(ctx context.Context, ct models.ConfirmationToken, cooldown time.Duration, max int) (string, error) {
+	token := uuidstr.GetUniqBase36(32)
+	result, err := r.db.ExecContext(ctx, `UPDATE `+ConfirmationTokenTable+` SET token = ?, resend_count = resend_count + 1, resent_at = NOW()
+		WHERE id = ? AND status = ? AND expires_at > NOW() AND resend_count < ? AND COALESCE(resent_at, created_at) <= DATE_SUB(NOW(), INTERVAL ? SECOND)`,
+		hashToken(token), ct.Id, ct.Status, max, int64(cooldown.Seconds()))
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to mark confirmation token as resent")
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return "", sql.ErrNoRows
+	}
+	return token, nil
+}
+
+func (r *ConfirmationTokenRepository) getResendableToken(ctx context.Context, condition string, tokenType string, arg any) (models.ConfirmationToken, error) {
+	var ct models.ConfirmationToken
+	err := r.db.QueryRowContext(ctx, resendableTokenSql+condition+` ORDER BY id DESC LIMIT 1`, tokenType, arg).
+		Scan(&ct.Id, &ct.Token, &ct.UserId, &ct.Type, &ct.Payload, &ct.Status, &ct.ExpiresAt, &ct.StatusChangedAt, &ct.CreatedAt)
 	if err != nil {
 		return models.ConfirmationToken{}, err
 	}
 	return ct, nil
 }
 
+// ExpireTokens marks NEW and PROCESSING tokens past their expiry as EXPIRED and returns their count.
+func (r *ConfirmationTokenRepository) ExpireTokens(ctx context.Context) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `UPDATE `+ConfirmationTokenTable+` SET status = ?, status_changed_at = NOW() WHERE status IN (?, ?) AND expires_at <= NOW()`,
+		models.ConfirmationTokenStatusExpired, models.ConfirmationTokenStatusNew, models.ConfirmationTokenStatusProcessing)
+	if err != nil {
+		return 0, errors.Wrap(err, "Failed to expire confirmation tokens")
+	}
+	return result.RowsAffected()
+}
+
+// DeleteFinishedOlderThan removes consumed, expired, cancelled and failed tokens whose status
+// changed more than age ago and returns their count.
+func (r *ConfirmationTokenRepository) DeleteFinishedOlderThan(ctx context.Context, age time.Duration) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM `+ConfirmationTokenTable+` WHERE status IN (?, ?, ?, ?) AND status_changed_at < DATE_SUB(NOW(), INTERVAL ? SECOND)`,
+		models.ConfirmationTokenStatusConsumed, models.ConfirmationTokenStatusExpired, models.ConfirmationTokenStatusCanceled, models.ConfirmationTokenStatusFailed, int64(age.Seconds()))
+	if err != nil {
+		return 0, errors.Wrap(err, "Failed to delete finished confirmation tokens")
+	}
+	return result.RowsAffected()
+}
+
 func (r *ConfirmationTokenRepository) updateStatus(ctx context.Context, token string, status string) error {
 	sql := `UPDATE ` + ConfirmationTokenTable + ` SET status = ?, status_changed_at = NOW() WHERE token = ? AND status != ?`
-	_, err := r.db.ExecContext(ctx, sql, status, token, status)
+	_, err := r.db.ExecContext(ctx, sql, status, hashToken(token), status)
 	if err != nil {
 		return errors.Wrap(err, "Failed to update confirmation token status")
 	}
@@ -111,7 +295,7 @@ func (r *ConfirmationTokenRepository) existsTokenByEmail(ctx context.Context, em
 func (r *ConfirmationTokenRepository) createToken(ctx context.Context, userId uint, tokenType string, payload []byte, days int) (string, error) {
 	confirmationToken := uuidstr.GetUniqBase36(32)
 	sql := fmt.Sprintf(createTokenSqlPattern, tokenType, days)
-	_, err := r.db.ExecContext(ctx, sql, confirmationToken, userId, payload)
+	_, err := r.db.ExecContext(ctx, sql, hashToken(confirmationToken), userId, payload)
 	if err != nil {
 		return "", errors.Wrap(err, "Failed to create reset token: "+sql)
 	}
@@ -1,8 +1,14 @@
 package repository
 
 import (
+	"backend/internal/tracing"
 	"context"
 	"database/sql"
+	"runtime"
+	"strings"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type DBExecutor interface {
@@ -10,3 +16,72 @@ type DBExecutor interface {
 	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
 	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
 }
+
+// tracedExecutor records a span for every query, named after the repository method that
+// runs it, e.g. "UserRepository.GetByEmail". The arguments are not recorded, so values
+// like password hashes and tokens stay out of the traces. Spans of QueryContext and
+// QueryRowContext end when the query returns, before its rows are read.
+type tracedExecutor struct {
+	db DBExecutor
+}
+
+// traced wraps the *sql.DB or *sql.Tx of a repository in a tracedExecutor.
+func traced(db DBExecutor) DBExecutor {
+	if _, ok := db.(tracedExecutor); ok || db == nil {
+		return db
+	}
+	return tracedExecutor{db: db}
+}
+
+func (e tracedExecutor) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := startQuery(ctx, query)
+	result, err := e.db.ExecContext(ctx, query, args...)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (e tracedExecutor) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, span := startQuery(ctx, query)
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	tracing.End(span, err)
+	return rows, err
+}
+
+func (e tracedExecutor) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, span := startQuery(ctx, query)
+	row := e.db.QueryRowContext(ctx, query, args...)
+	err := row.Err()
+	if err == sql.ErrNoRows {
+		err = nil
+	}
+	tracing.End(span, err)
+	return row
+}
+
+func startQuery(ctx context.Context, query string) (context.Context, trace.Span) {
+	ctx, span := tracing.Tracer().Start(ctx, "db.query", trace.WithSpanKind(trace.SpanKindClient))
+	if !span.IsRecording() {
+		return ctx, span
+	}
+	query = strings.Join(strings.Fields(query), " ")
+	operation, _, _ := strings.Cut(query, " ")
+	if name := repositoryMethod(); name != "" {
+		span.SetName(name)
+	}
+	span.SetAttributes(semconv.DBSystemMySQL, semconv.DBOperationName(strings.ToUpper(operation)), semconv.DBQueryText(query))
+	return ctx, span
+}
+
+// repositoryMethod returns the repository method running the query, skipping
+// runtime.Callers, repositoryMethod, startQuery and the tracedExecutor method.
+func repositoryMethod() string {
+	var pcs [1]uintptr
+	if runtime.Callers(4, pcs[:]) == 0 {
+		return ""
+	}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	// e.g. backend/internal/repository.(*UserRepository).GetByEmail
+	name := frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+	name = strings.TrimPrefix(name, "repository.")
+	return strings.NewReplacer("(*", "", ")", "").Replace(name)
+}
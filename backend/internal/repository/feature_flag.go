@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"backend/internal/models"
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	FeatureFlagTable         = "feature_flags"
+	FeatureFlagOverrideTable = "feature_flag_overrides"
+)
+
+type FeatureFlagRepository struct {
+	db DBExecutor
+}
+
+func NewFeatureFlagRepository(db DBExecutor) *FeatureFlagRepository {
+	return &FeatureFlagRepository{db: traced(db)}
+}
+
+func (r *FeatureFlagRepository) GetAll(ctx context.Context) ([]models.FeatureFlag, error) {
+	list := []models.FeatureFlag{}
+	rows, err := r.db.QueryContext(ctx, `SELECT name, enabled, rollout_percent, updated_at FROM `+FeatureFlagTable+` ORDER BY name`)
+	if err != nil {
+		return list, errors.Wrap(err, "get feature flags")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var flag models.FeatureFlag
+		if err := rows.Scan(&flag.Name, &flag.Enabled, &flag.RolloutPercent, &flag.UpdatedAt); err != nil {
+			return list, err
+		}
+		list = append(list, flag)
+	}
+	return list, rows.Err()
+}
+
+func (r *FeatureFlagRepository) GetOverrides(ctx context.Context) ([]models.FeatureFlagOverride, error) {
+	list := []models.FeatureFlagOverride{}
+	rows, err := r.db.QueryContext(ctx, `SELECT flag_name, user_id, enabled FROM `+FeatureFlagOverrideTable+` ORDER BY flag_name, user_id`)
+	if err != nil {
+		return list, errors.Wrap(err, "get feature flag overrides")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var override models.FeatureFlagOverride
+		if err := rows.Scan(&override.FlagName, &override.UserId, &override.Enabled); err != nil {
+			return list, err
+		}
+		list = append(list, override)
+	}
+	return list, rows.Err()
+}
+
+// Save creates the flag or updates its state.
+func (r *FeatureFlagRepository) Save(ctx context.Context, name string, enabled bool, rolloutPercent int) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO `+FeatureFlagTable+` (name, enabled, rollout_percent) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE enabled = VALUES(enabled), rollout_percent = VALUES(rollout_percent)`, name, enabled, rolloutPercent)
+	if err != nil {
+		return errors.Wrap(err, "save feature flag")
+	}
+	return nil
+}
+
+func (r *FeatureFlagRepository) SaveOverride(ctx context.Context, name string, userId uint, enabled bool) error {
+	_, err := r.db.ExecContext(ctx, `INSERT INTO `+FeatureFlagOverrideTable+` (flag_name, user_id, enabled) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE enabled = VALUES(enabled)`, name, userId, enabled)
+	if err != nil {
+		return errors.Wrap(err, "save feature flag override")
+	}
+	return nil
+}
+
+func (r *FeatureFlagRepository) DeleteOverride(ctx context.Context, name string, userId uint) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM `+FeatureFlagOverrideTable+` WHERE flag_name = ? AND user_id = ?`, name, userId)
+	if err != nil {
+		return errors.Wrap(err, "delete feature flag override")
+	}
+	return nil
+}
+
+func (r *FeatureFlagRepository) DeleteOverridesByUserId(ctx context.Context, userId uint) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM `+FeatureFlagOverrideTable+` WHERE user_id = ?`, userId)
+	return err
+}
@@ -11,7 +11,7 @@ type LanguageRepository struct {
 }
 
 func NewLanguageRepository(db DBExecutor) *LanguageRepository {
-	return &LanguageRepository{db: db}
+	return &LanguageRepository{db: traced(db)}
 }
 func (r *LanguageRepository) Get(ctx context.Context) ([]models.Language, error) {
 	list := []models.Language{}
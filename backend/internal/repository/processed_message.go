@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const ProcessedMessageTable = "processed_messages"
+
+// ProcessedMessageRepository is the ledger of handled queue messages used to skip
+// redelivered duplicates.
+type ProcessedMessageRepository struct {
+	db DBExecutor
+}
+
+func NewProcessedMessageRepository(db DBExecutor) *ProcessedMessageRepository {
+	return &ProcessedMessageRepository{db: traced(db)}
+}
+
+func (r *ProcessedMessageRepository) Exists(ctx context.Context, messageId string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT 1 FROM `+ProcessedMessageTable+` WHERE message_id = ?`, messageId).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "check processed message")
+	}
+	return true, nil
+}
+
+// Record adds the message to the ledger. Recording an already processed message is a no-op.
+func (r *ProcessedMessageRepository) Record(ctx context.Context, messageId, task string) error {
+	_, err := r.db.ExecContext(ctx, `INSERT IGNORE INTO `+ProcessedMessageTable+` (message_id, task, processed_at) VALUES (?, ?, NOW())`, messageId, task)
+	if err != nil {
+		return errors.Wrap(err, "record processed message")
+	}
+	return nil
+}
+
+// DeleteOlderThan removes ledger entries processed more than age ago and returns their count.
+func (r *ProcessedMessageRepository) DeleteOlderThan(ctx context.Context, age time.Duration) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM `+ProcessedMessageTable+` WHERE processed_at < DATE_SUB(NOW(), INTERVAL ? SECOND)`, int64(age.Seconds()))
+	if err != nil {
+		return 0, errors.Wrap(err, "delete processed messages")
+	}
+	return result.RowsAffected()
+}
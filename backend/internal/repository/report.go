@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"backend/internal/models"
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ReportTable = "reports"
+
+	reportColumns = `id, user_id, type, format, params, status, output_path, error, created_at, started_at, finished_at`
+)
+
+type ReportRepository struct {
+	db DBExecutor
+}
+
+func NewReportRepository(db DBExecutor) *ReportRepository {
+	return &ReportRepository{db: traced(db)}
+}
+
+func (r *ReportRepository) Create(ctx context.Context, userId uint, reportType, format string, params []byte) (uint, error) {
+	result, err := r.db.ExecContext(ctx, `INSERT INTO `+ReportTable+` (user_id, type, format, params, status, created_at) VALUES (?, ?, ?, ?, ?, NOW())`,
+		userId, reportType, format, params, models.ReportStatusQueued)
+	if err != nil {
+		return 0, errors.Wrap(err, "create report")
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, errors.Wrap(err, "get report id")
+	}
+	return uint(id), nil
+}
+
+func (r *ReportRepository) GetById(ctx context.Context, id uint) (models.Report, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+reportColumns+` FROM `+ReportTable+` WHERE id = ?`, id)
+	return scanReport(row)
+}
+
+func (r *ReportRepository) GetByIdAndUserId(ctx context.Context, id, userId uint) (models.Report, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+reportColumns+` FROM `+ReportTable+` WHERE id = ? AND user_id = ?`, id, userId)
+	return scanReport(row)
+}
+
+// MarkRunning moves a queued (or previously failed) report into the running state.
+// It returns false when the report has already been generated.
+func (r *ReportRepository) MarkRunning(ctx context.Context, id uint) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `UPDATE `+ReportTable+` SET status = ?, started_at = NOW(), error = NULL WHERE id = ? AND status <> ?`,
+		models.ReportStatusRunning, id, models.ReportStatusDone)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *ReportRepository) MarkDone(ctx context.Context, id uint, outputPath string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE `+ReportTable+` SET status = ?, output_path = ?, finished_at = NOW() WHERE id = ?`,
+		models.ReportStatusDone, outputPath, id)
+	return err
+}
+
+func (r *ReportRepository) MarkFailed(ctx context.Context, id uint, reason string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE `+ReportTable+` SET status = ?, error = ?, finished_at = NOW() WHERE id = ?`,
+		models.ReportStatusFailed, reason, id)
+	return err
+}
+
+func (r *ReportRepository) DeleteByUserId(ctx context.Context, userId uint) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM `+ReportTable+` WHERE user_id = ?`, userId)
+	return err
+}
+
+func scanReport(row *sql.Row) (models.Report, error) {
+	var rep models.Report
+	var outputPath, reportError sql.NullString
+	var startedAt, finishedAt sql.NullTime
+	err := row.Scan(&rep.Id, &rep.UserId, &rep.Type, &rep.Format, &rep.Params, &rep.Status, &outputPath, &reportError, &rep.CreatedAt, &startedAt, &finishedAt)
+	if err != nil {
+		return models.Report{}, err
+	}
+	rep.OutputPath = outputPath.String
+	rep.Error = reportError.String
+	rep.StartedAt = startedAt.Time
+	rep.FinishedAt = finishedAt.Time
+	return rep, nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"backend/internal/models"
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ScheduledJobTable = "scheduled_jobs"
+
+	scheduledJobColumns = `id, queue, task, data, headers, run_at, published_at, created_at`
+)
+
+type ScheduledJobRepository struct {
+	db DBExecutor
+}
+
+func NewScheduledJobRepository(db DBExecutor) *ScheduledJobRepository {
+	return &ScheduledJobRepository{db: traced(db)}
+}
+
+// Create schedules the task to be published after delay. The run time is computed by the
+// database, so it is consistent with NOW() used when looking for due jobs.
+func (r *ScheduledJobRepository) Create(ctx context.Context, queue, task string, data, headers []byte, delay time.Duration) (uint, error) {
+	result, err := r.db.ExecContext(ctx, `INSERT INTO `+ScheduledJobTable+` (queue, task, data, headers, run_at, created_at) VALUES (?, ?, ?, ?, DATE_ADD(NOW(), INTERVAL ? SECOND), NOW())`,
+		queue, task, data, headers, int64(max(delay, 0).Seconds()))
+	if err != nil {
+		return 0, errors.Wrap(err, "create scheduled job")
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, errors.Wrap(err, "get scheduled job id")
+	}
+	return uint(id), nil
+}
+
+// GetDue returns up to limit unpublished jobs of the given queues whose run time has come,
+// oldest first. Jobs of other queues are not returned, so they cannot fill every batch.
+func (r *ScheduledJobRepository) GetDue(ctx context.Context, queues []string, limit int) ([]models.ScheduledJob, error) {
+	list := []models.ScheduledJob{}
+	if len(queues) == 0 {
+		return list, nil
+	}
+	args := make([]any, 0, len(queues)+1)
+	for _, queue := range queues {
+		args = append(args, queue)
+	}
+	args = append(args, limit)
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(queues)), ", ")
+	rows, err := r.db.QueryContext(ctx, `SELECT `+scheduledJobColumns+` FROM `+ScheduledJobTable+` WHERE published_at IS NULL AND run_at <= NOW() AND queue IN (`+placeholders+`) ORDER BY run_at, id LIMIT ?`, args...)
+	if err != nil {
+		return list, errors.Wrap(err, "get due scheduled jobs")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var job models.ScheduledJob
+		var publishedAt sql.NullTime
+		if err := rows.Scan(&job.Id, &job.Queue, &job.Task, &job.Data, &job.Headers, &job.RunAt, &publishedAt, &job.CreatedAt); err != nil {
+			return list, err
+		}
+		job.PublishedAt = publishedAt.Time
+		list = append(list, job)
+	}
+	return list, rows.Err()
+}
+
+func (r *ScheduledJobRepository) MarkPublished(ctx context.Context, id uint) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE `+ScheduledJobTable+` SET published_at = NOW() WHERE id = ?`, id)
+	if err != nil {
+		return errors.Wrap(err, "mark scheduled job published")
+	}
+	return nil
+}
+
+// DeletePublishedOlderThan removes jobs published more than age ago and returns their count.
+func (r *ScheduledJobRepository) DeletePublishedOlderThan(ctx context.Context, age time.Duration) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM `+ScheduledJobTable+` WHERE published_at < DATE_SUB(NOW(), INTERVAL ? SECOND)`, int64(age.Seconds()))
+	if err != nil {
+		return 0, errors.Wrap(err, "delete published scheduled jobs")
+	}
+	return result.RowsAffected()
+}
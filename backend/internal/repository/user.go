@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"strings"
+	"time"
 )
 
 type UserRepository struct {
@@ -12,7 +13,7 @@ type UserRepository struct {
 }
 
 func NewUserRepository(db DBExecutor) *UserRepository {
-	return &UserRepository{db: db}
+	return &UserRepository{db: traced(db)}
 }
 
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (models.User, error) {
@@ -127,3 +128,85 @@ func (r *UserRepository) UpdatePasswordById(ctx context.Context, userId uint, ne
 	}
 	return nil
 }
+
+// ScheduleDeletion marks the user for deletion at the given time. It returns false
+// when a deletion is already scheduled for this user.
+func (r *UserRepository) ScheduleDeletion(ctx context.Context, userId uint, at time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET deletion_scheduled_at = ? WHERE id = ? AND deletion_scheduled_at IS NULL`,
+		at, userId)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *UserRepository) CancelDeletion(ctx context.Context, userId uint) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE users SET deletion_scheduled_at = NULL WHERE id = ?`,
+		userId)
+	return err
+}
+
+func (r *UserRepository) GetIdsDueForDeletion(ctx context.Context, limit int) ([]uint, error) {
+	ids := []uint{}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id FROM users WHERE deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= NOW()
+		ORDER BY deletion_scheduled_at LIMIT ?`, limit)
+	if err != nil {
+		return ids, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// DeleteScheduled removes the user row, but only if its deletion is still scheduled and due.
+// It returns false when the deletion was cancelled in the meantime.
+func (r *UserRepository) DeleteScheduled(ctx context.Context, userId uint) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM users WHERE id = ? AND deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= NOW()`,
+		userId)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// StreamRegisteredBetween calls fn for every user registered in [from, to) without loading
+// the whole result set into memory.
+func (r *UserRepository) StreamRegisteredBetween(ctx context.Context, from, to time.Time, fn func(models.User) error) error {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT id, name, email, registered_at, confirmed_at
+        FROM users WHERE registered_at >= ? AND registered_at < ? ORDER BY id`, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.Id, &u.Name, &u.Email, &u.RegisteredAt, &u.ConfirmedAt); err != nil {
+			return err
+		}
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
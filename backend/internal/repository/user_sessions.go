@@ -1,9 +1,11 @@
 package repository
 
 import (
+	"backend/internal/models"
 	"backend/pkg/logger"
 	"context"
 	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
 	"time"
 )
@@ -17,7 +19,7 @@ type UserSessionsRepository struct {
 }
 
 func NewUserSessionsRepository(db DBExecutor) *UserSessionsRepository {
-	return &UserSessionsRepository{db: db}
+	return &UserSessionsRepository{db: traced(db)}
 }
 func (r *UserSessionsRepository) Create(ctx context.Context, userId uint, token string, expiresAt time.Time, userAgent string, ip string) error {
 	hash := hashToken(token)
@@ -62,6 +64,66 @@ func (r *UserSessionsRepository) Revoke(ctx context.Context, userId uint, token
 	return nil
 }
 
+func (r *UserSessionsRepository) RevokeAllByUserId(ctx context.Context, userId uint) error {
+	sql := `UPDATE ` + UserSessionsTable + ` SET revoked_at=NOW() WHERE user_id=? AND revoked_at IS NULL`
+	result, err := r.db.ExecContext(ctx, sql, userId)
+	if err != nil {
+		return err
+	}
+	revoked, _ := result.RowsAffected()
+	logger.InfoCtx(ctx, "Sessions revoked: %d", revoked)
+	return nil
+}
+func (r *UserSessionsRepository) DeleteByUserId(ctx context.Context, userId uint) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM `+UserSessionsTable+` WHERE user_id=?`, userId)
+	return err
+}
+
+func (r *UserSessionsRepository) GetByUserId(ctx context.Context, userId uint) ([]models.UserSessions, error) {
+	list := []models.UserSessions{}
+	rows, err := r.db.QueryContext(ctx, `SELECT id, user_id, created_at, expires_at, refreshed_at, revoked_at, user_agent, ip FROM `+UserSessionsTable+` WHERE user_id = ? ORDER BY created_at`, userId)
+	if err != nil {
+		return list, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var us models.UserSessions
+		var refreshedAt, revokedAt sql.NullTime
+		if err := rows.Scan(&us.Id, &us.UserId, &us.CreatedAt, &us.ExpiresAt, &refreshedAt, &revokedAt, &us.UserAgent, &us.Ip); err != nil {
+			return list, err
+		}
+		us.RefreshedAt = refreshedAt.Time
+		us.RevokedAt = revokedAt.Time
+		list = append(list, us)
+	}
+	return list, rows.Err()
+}
+
+// StreamCreatedBetween calls fn for every session created in [from, to) without loading
+// the whole result set into memory.
+func (r *UserSessionsRepository) StreamCreatedBetween(ctx context.Context, from, to time.Time, fn func(models.UserSessions) error) error {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, user_id, created_at, expires_at, refreshed_at, revoked_at, user_agent, ip FROM `+UserSessionsTable+` WHERE created_at >= ? AND created_at < ? ORDER BY id`, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var us models.UserSessions
+		var refreshedAt, revokedAt sql.NullTime
+		if err := rows.Scan(&us.Id, &us.UserId, &us.CreatedAt, &us.ExpiresAt, &refreshedAt, &revokedAt, &us.UserAgent, &us.Ip); err != nil {
+			return err
+		}
+		us.RefreshedAt = refreshedAt.Time
+		us.RevokedAt = revokedAt.Time
+		if err := fn(us); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 func hashToken(token string) string {
 	hash := sha256.Sum256([]byte(token))
 	return hex.EncodeToString(hash[:]) // 64 znaki
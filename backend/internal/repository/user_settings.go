@@ -10,7 +10,7 @@ type UserSettingsRepository struct {
 }
 
 func NewUserSettingsRepository(db DBExecutor) *UserSettingsRepository {
-	return &UserSettingsRepository{db: db}
+	return &UserSettingsRepository{db: traced(db)}
 }
 func (r *UserSettingsRepository) Create(ctx context.Context, userId uint, languageId uint8) error {
 	_, err := r.db.ExecContext(ctx, `
@@ -43,4 +43,9 @@ func (r *UserSettingsRepository) Update(ctx context.Context, us models.UserSetti
 			us.Id,
 	)
 	return err
-}
\ No newline at end of file
+}
+
+func (r *UserSettingsRepository) DeleteByUserId(ctx context.Context, userId uint) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM user_settings WHERE user_id = ?`, userId)
+	return err
+}
@@ -0,0 +1,24 @@
+package response
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"backend/internal/apicodes"
+)
+
+func SetAccountDeletionScheduledResponse(w http.ResponseWriter, ctx context.Context, scheduledFor time.Time) {
+	data := map[string]string{
+		"scheduled_for": scheduledFor.Format("2006-01-02 15:04:05"),
+	}
+	SuccessDataCodeResponse(w, ctx, data, apicodes.API_Account_Deletion_Scheduled)
+}
+
+func AccountDeletionErrorInvalidCredentials(w http.ResponseWriter) {
+	apiErrorResponse(w, http.StatusUnauthorized, apicodes.API_Account_Deletion_Invalid_Credentials)
+}
+
+func AccountDeletionErrorAlreadyScheduled(w http.ResponseWriter) {
+	apiErrorResponse(w, http.StatusConflict, apicodes.API_Account_Deletion_Already_Scheduled)
+}
@@ -21,3 +21,7 @@ func SetConfirmFailureResponse(w http.ResponseWriter) {
 func SetConfirmInvalidTokenResponse(w http.ResponseWriter) {
 	apiErrorResponse(w, http.StatusBadRequest, apicodes.API_Confirm_Invalid_Token)
 }
+
+func SetConfirmAlreadyUsedResponse(w http.ResponseWriter) {
+	apiErrorResponse(w, http.StatusConflict, apicodes.API_Confirm_Already_Used)
+}
@@ -0,0 +1,20 @@
+package response
+
+import (
+	"context"
+	"net/http"
+
+	"backend/internal/apicodes"
+)
+
+func ConfirmationResendSuccessResponse(w http.ResponseWriter, ctx context.Context) {
+	SuccessCodeResponse(w, ctx, apicodes.API_Confirmation_Resend_Success)
+}
+
+func ConfirmationResendErrorNotFound(w http.ResponseWriter) {
+	apiErrorResponse(w, http.StatusNotFound, apicodes.API_Confirmation_Resend_Not_Found)
+}
+
+func ConfirmationResendErrorTooManyRequests(w http.ResponseWriter) {
+	apiErrorResponse(w, http.StatusTooManyRequests, apicodes.API_Confirmation_Resend_Too_Many_Requests)
+}
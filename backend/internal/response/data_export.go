@@ -0,0 +1,12 @@
+package response
+
+import (
+	"context"
+	"net/http"
+
+	"backend/internal/apicodes"
+)
+
+func DataExportRequestedResponse(w http.ResponseWriter, ctx context.Context) {
+	SuccessCodeResponse(w, ctx, apicodes.API_Data_Export_Requested)
+}
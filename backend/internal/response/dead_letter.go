@@ -0,0 +1,25 @@
+package response
+
+import (
+	"context"
+	"net/http"
+
+	"backend/internal/queue"
+)
+
+type deadLettersResponseData struct {
+	Queue    string             `json:"queue"`
+	Messages []queue.DeadLetter `json:"messages"`
+}
+
+func SetDeadLettersResponse(w http.ResponseWriter, ctx context.Context, queueName string, list []queue.DeadLetter) {
+	SuccessDataResponse(w, ctx, deadLettersResponseData{Queue: queueName, Messages: list})
+}
+
+func SetDeadLettersReplayedResponse(w http.ResponseWriter, ctx context.Context, replayed int) {
+	SuccessDataResponse(w, ctx, map[string]int{"replayed": replayed})
+}
+
+func SetDeadLettersPurgedResponse(w http.ResponseWriter, ctx context.Context, purged int) {
+	SuccessDataResponse(w, ctx, map[string]int{"purged": purged})
+}
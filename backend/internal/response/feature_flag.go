@@ -0,0 +1,25 @@
+package response
+
+import (
+	"context"
+	"net/http"
+
+	"backend/internal/apicodes"
+	"backend/internal/featureflag"
+)
+
+func SetFeatureFlagsResponse(w http.ResponseWriter, ctx context.Context, flags []featureflag.Flag) {
+	SuccessDataResponse(w, ctx, flags)
+}
+
+func FeatureFlagUpdatedResponse(w http.ResponseWriter, ctx context.Context) {
+	SuccessCodeResponse(w, ctx, apicodes.API_Feature_Flag_Updated)
+}
+
+func FeatureDisabledErrorResponse(w http.ResponseWriter) {
+	apiErrorResponse(w, http.StatusForbidden, apicodes.API_Feature_Disabled)
+}
+
+func FeatureFlagErrorNotFound(w http.ResponseWriter) {
+	apiErrorResponse(w, http.StatusNotFound, apicodes.API_Feature_Flag_Not_Found)
+}
@@ -11,6 +11,9 @@ func httpErrorResponse(w http.ResponseWriter, status int) {
 func InternalServerError(w http.ResponseWriter) {
 	httpErrorResponse(w, http.StatusInternalServerError)
 }
+func ForbiddenErrorResponse(w http.ResponseWriter) {
+	httpErrorResponse(w, http.StatusForbidden)
+}
 func NotFoundErrorResponse(w http.ResponseWriter) {
 	httpErrorResponse(w, http.StatusNotFound)
 }
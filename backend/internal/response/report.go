@@ -0,0 +1,61 @@
+package response
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"backend/internal/apicodes"
+	"backend/internal/models"
+)
+
+type reportResponseData struct {
+	Id         uint   `json:"id"`
+	Type       string `json:"type"`
+	Format     string `json:"format"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	StartedAt  string `json:"started_at,omitempty"`
+	FinishedAt string `json:"finished_at,omitempty"`
+}
+
+func ReportQueuedResponse(w http.ResponseWriter, ctx context.Context, reportId uint) {
+	data := map[string]uint{
+		"id": reportId,
+	}
+	SuccessDataCodeResponse(w, ctx, data, apicodes.API_Report_Queued)
+}
+
+func SetReportSuccessResponse(w http.ResponseWriter, ctx context.Context, report models.Report) {
+	data := reportResponseData{
+		Id:         report.Id,
+		Type:       report.Type,
+		Format:     report.Format,
+		Status:     report.Status,
+		Error:      report.Error,
+		CreatedAt:  formatReportTime(report.CreatedAt),
+		StartedAt:  formatReportTime(report.StartedAt),
+		FinishedAt: formatReportTime(report.FinishedAt),
+	}
+	SuccessDataResponse(w, ctx, data)
+}
+
+func ReportErrorForbidden(w http.ResponseWriter) {
+	apiErrorResponse(w, http.StatusForbidden, apicodes.API_Report_Forbidden)
+}
+
+func ReportErrorNotFound(w http.ResponseWriter) {
+	apiErrorResponse(w, http.StatusNotFound, apicodes.API_Report_Not_Found)
+}
+
+func ReportErrorNotReady(w http.ResponseWriter) {
+	apiErrorResponse(w, http.StatusConflict, apicodes.API_Report_Not_Ready)
+}
+
+func formatReportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
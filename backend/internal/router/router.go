@@ -5,21 +5,24 @@ import (
 	"net/http"
 
 	"backend/config"
+	"backend/internal/featureflag"
 	"backend/internal/handler"
 	"backend/internal/middleware"
 
 	"github.com/go-chi/chi/v5"
-	amqp "github.com/rabbitmq/amqp091-go"
+	"backend/pkg/rabbitmq"
 )
 
-func SetupRouter(h *handler.Handler, cfg *config.Config, db *sql.DB, rabbitConn *amqp.Connection) http.Handler {
+func SetupRouter(h *handler.Handler, cfg *config.Config, db *sql.DB, rabbitConn *rabbitmq.Connection, flags *featureflag.Store) http.Handler {
 	r := chi.NewRouter()
 
 	// Rejestracja middleware
 	r.Use(middleware.IP)
 	r.Use(middleware.Config(cfg))
 	r.Use(middleware.WithServices(db, rabbitConn))
+	r.Use(middleware.FeatureFlags(flags))
 	r.Use(middleware.RequestID)
+	r.Use(middleware.Tracing)
 	r.Use(middleware.AccessLog)
 	r.Use(middleware.Recoverer)
 
@@ -30,21 +33,42 @@ func SetupRouter(h *handler.Handler, cfg *config.Config, db *sql.DB, rabbitConn
 	r.Post("/login", h.LoginHandler)
 	r.Get("/cfg", h.CfgHandler)
 	r.Post("/register", h.RegisterHandler)
+	r.Post("/register/resend", h.RegisterResendHandler)
 	r.Post("/reset-password", h.ResetPasswordHandler)
 	r.Post("/password-change/{token}", h.PasswordChangeHandler)
 	r.Get("/confirm/{token}", h.ConfirmHandler)
 	r.Get("/logout", h.LogoutHandler)
+	r.Get("/export/{file}", h.DataExportDownloadHandler)
 
 
 	r.Group(func(r chi.Router) {
 		r.Use(middleware.RefreshSession)
 		r.Use(middleware.AuthOnly)
 		r.Get("/me", h.MeHandler)
+		r.Delete("/me", h.DeleteMeHandler)
+		r.Post("/me/export", h.DataExportHandler)
+		r.Post("/reports", h.CreateReportHandler)
+		r.Get("/reports/{id}", h.GetReportHandler)
+		r.Get("/reports/{id}/file", h.ReportFileHandler)
 		r.Get("/ping", h.PingHandler)
 		// tu możesz dodać inne chronione ścieżki
 		//r.Get("/me", h.MeHandler)
 		r.Post("/settings", h.SettingsHandler)
 		r.Post("/email_change", h.EmailChangeHandler)
+		r.Post("/email_change/resend", h.EmailChangeResendHandler)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RefreshSession)
+		r.Use(middleware.AuthOnly)
+		r.Use(middleware.AdminOnly)
+		r.Get("/admin/queues/{queue}/dlq", h.ListDeadLettersHandler)
+		r.Post("/admin/queues/{queue}/dlq/replay", h.ReplayDeadLettersHandler)
+		r.Delete("/admin/queues/{queue}/dlq", h.PurgeDeadLettersHandler)
+		r.Get("/admin/feature_flags", h.ListFeatureFlagsHandler)
+		r.Put("/admin/feature_flags/{name}", h.SetFeatureFlagHandler)
+		r.Put("/admin/feature_flags/{name}/users/{userId}", h.SetFeatureFlagOverrideHandler)
+		r.Delete("/admin/feature_flags/{name}/users/{userId}", h.DeleteFeatureFlagOverrideHandler)
 	})
 
 	// 🔹 Obsługa 404 i 405
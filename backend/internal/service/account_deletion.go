@@ -0,0 +1,90 @@
+package service
+
+import (
+	"backend/internal/apperrors"
+	"backend/internal/contexthelper"
+	"backend/internal/payload"
+	"backend/internal/queue"
+	"backend/internal/repository"
+	"backend/pkg/logger"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type AccountDeletionService struct {
+	confirmationTokenRepo *repository.ConfirmationTokenRepository
+	userRepo              *repository.UserRepository
+	sessionRepo           *repository.UserSessionsRepository
+}
+
+func NewAccountDeletionService(ctRepo *repository.ConfirmationTokenRepository, uRepo *repository.UserRepository, sRepo *repository.UserSessionsRepository) *AccountDeletionService {
+	return &AccountDeletionService{
+		confirmationTokenRepo: ctRepo,
+		userRepo:              uRepo,
+		sessionRepo:           sRepo,
+	}
+}
+
+// ScheduleDeletion verifies the user's password and schedules the account for deletion
+// after the configured grace period. All sessions of the user are revoked. The schedule,
+// the cancellation token and the revocation are stored in one transaction.
+func (s *AccountDeletionService) ScheduleDeletion(ctx context.Context, userId uint, password string) (time.Time, error) {
+	user, err := s.userRepo.GetById(ctx, userId)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "user not found")
+	}
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	if err != nil {
+		return time.Time{}, apperrors.NewAccountDeletionInvalidCredentialsError("Invalid password")
+	}
+
+	cfg := contexthelper.GetConfig(ctx)
+	scheduledFor := time.Now().Add(time.Duration(cfg.AccountDeletion.GracePeriodDays) * 24 * time.Hour)
+	jsonPayload, err := json.Marshal(payload.AccountDeletionPayload{ScheduledFor: scheduledFor})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	tx, err := contexthelper.GetDb(ctx).BeginTx(ctx, nil)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "begin transaction")
+	}
+	defer tx.Rollback()
+
+	scheduled, err := repository.NewUserRepository(tx).ScheduleDeletion(ctx, user.Id, scheduledFor)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !scheduled {
+		return time.Time{}, apperrors.NewAccountDeletionAlreadyScheduledError("Account deletion already scheduled")
+	}
+	confirmationToken, err := repository.NewConfirmationTokenRepository(tx).CreateAccountDeletionToken(ctx, user.Id, jsonPayload, cfg.AccountDeletion.GracePeriodDays)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := repository.NewUserSessionsRepository(tx).RevokeAllByUserId(ctx, user.Id); err != nil {
+		return time.Time{}, errors.Wrap(err, "revoke sessions")
+	}
+	if err := tx.Commit(); err != nil {
+		return time.Time{}, errors.Wrap(err, "commit account deletion")
+	}
+
+	rabbitConn := contexthelper.GetRabbitConn(ctx)
+	err = queue.AccountDeletionEmailTask.Publish(ctx, rabbitConn, queue.AccountDeletionEmailData{AccountDeletionToken: confirmationToken})
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to enqueue account deletion email task: %v", err)
+	}
+	return scheduledFor, nil
+}
+
+func (s *AccountDeletionService) CancelDeletion(ctx context.Context, userId uint) error {
+	err := s.userRepo.CancelDeletion(ctx, userId)
+	if err != nil {
+		return errors.Wrap(err, "cancel account deletion")
+	}
+	return nil
+}
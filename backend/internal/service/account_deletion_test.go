@@ -0,0 +1,197 @@
+package service_test
+
+import (
+	"backend/config"
+	"backend/internal/apperrors"
+	"backend/internal/contexthelper"
+	"backend/internal/repository"
+	"backend/internal/service"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestAccountDeletionService_ScheduleDeletion_InvalidPassword(t *testing.T) {
+	ctx := contexthelper.SetConfig(context.Background(), &config.Config{
+		AccountDeletion: config.AccountDeletionConfig{GracePeriodDays: 14},
+	})
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	regTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Mock user lookup by id
+	mock.ExpectQuery("SELECT.*FROM users WHERE id").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name", "email", "password", "registered_at", "confirmed_at"}).
+			AddRow(1, "testuser", "test@example.com", string(hashedPassword), regTime, regTime),
+	)
+
+	deletionService := service.NewAccountDeletionService(
+		repository.NewConfirmationTokenRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewUserSessionsRepository(db),
+	)
+	_, err = deletionService.ScheduleDeletion(ctx, 1, "wrongpassword")
+
+	if !apperrors.IsAccountDeletionInvalidCredentialsError(err) {
+		t.Errorf("expected invalid credentials error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAccountDeletionService_ScheduleDeletion(t *testing.T) {
+	ctx := contexthelper.SetConfig(context.Background(), &config.Config{
+		AccountDeletion: config.AccountDeletionConfig{GracePeriodDays: 14},
+	})
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+	ctx = contexthelper.SetDb(ctx, db)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	regTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT.*FROM users WHERE id").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name", "email", "password", "registered_at", "confirmed_at"}).
+			AddRow(1, "testuser", "test@example.com", string(hashedPassword), regTime, regTime),
+	)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users SET deletion_scheduled_at").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO confirmation_tokens").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE user_sessions SET revoked_at").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	deletionService := service.NewAccountDeletionService(
+		repository.NewConfirmationTokenRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewUserSessionsRepository(db),
+	)
+	scheduledFor, err := deletionService.ScheduleDeletion(ctx, 1, "password123")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if until := time.Until(scheduledFor); until < 13*24*time.Hour || until > 14*24*time.Hour {
+		t.Errorf("expected deletion in 14 days, got %v", scheduledFor)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAccountDeletionService_ScheduleDeletion_RevokeFailureRollsBack(t *testing.T) {
+	ctx := contexthelper.SetConfig(context.Background(), &config.Config{
+		AccountDeletion: config.AccountDeletionConfig{GracePeriodDays: 14},
+	})
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+	ctx = contexthelper.SetDb(ctx, db)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	regTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT.*FROM users WHERE id").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name", "email", "password", "registered_at", "confirmed_at"}).
+			AddRow(1, "testuser", "test@example.com", string(hashedPassword), regTime, regTime),
+	)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users SET deletion_scheduled_at").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO confirmation_tokens").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE user_sessions SET revoked_at").WillReturnError(errors.New("connection lost"))
+	mock.ExpectRollback()
+
+	deletionService := service.NewAccountDeletionService(
+		repository.NewConfirmationTokenRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewUserSessionsRepository(db),
+	)
+	_, err = deletionService.ScheduleDeletion(ctx, 1, "password123")
+	if err == nil {
+		t.Fatal("expected an error when the sessions cannot be revoked")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAccountDeletionService_ScheduleDeletion_AlreadyScheduled(t *testing.T) {
+	ctx := contexthelper.SetConfig(context.Background(), &config.Config{
+		AccountDeletion: config.AccountDeletionConfig{GracePeriodDays: 14},
+	})
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+	ctx = contexthelper.SetDb(ctx, db)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	regTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT.*FROM users WHERE id").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name", "email", "password", "registered_at", "confirmed_at"}).
+			AddRow(1, "testuser", "test@example.com", string(hashedPassword), regTime, regTime),
+	)
+	// Mock schedule update matching no rows (deletion already scheduled)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users SET deletion_scheduled_at").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	deletionService := service.NewAccountDeletionService(
+		repository.NewConfirmationTokenRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewUserSessionsRepository(db),
+	)
+	_, err = deletionService.ScheduleDeletion(ctx, 1, "password123")
+
+	if !apperrors.IsAccountDeletionAlreadyScheduledError(err) {
+		t.Errorf("expected already scheduled error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAccountDeletionService_CancelDeletion(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE users SET deletion_scheduled_at = NULL").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	deletionService := service.NewAccountDeletionService(
+		repository.NewConfirmationTokenRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewUserSessionsRepository(db),
+	)
+	if err := deletionService.CancelDeletion(ctx, 1); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"backend/internal/apperrors"
+	"backend/internal/contexthelper"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// resendToken counts a resend of ct within the limits of the confirmation_tokens config and
+// returns the new token to send. Links of earlier emails stop working.
+func resendToken(ctx context.Context, repo *repository.ConfirmationTokenRepository, ct models.ConfirmationToken) (string, error) {
+	cfg := contexthelper.GetConfig(ctx).ConfirmationTokens
+	cooldown := time.Duration(cfg.ResendCooldownSeconds) * time.Second
+	token, err := repo.MarkResent(ctx, ct, cooldown, cfg.ResendMax)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", apperrors.NewConfirmationResendTooManyRequestsError("Confirmation email was resent too recently or too often")
+	}
+	return token, err
+}
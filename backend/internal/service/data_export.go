@@ -0,0 +1,51 @@
+package service
+
+import (
+	"backend/internal/contexthelper"
+	"backend/internal/queue"
+	"backend/pkg/signedlink"
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+var ErrDataExportNotFound = errors.New("data export not found")
+
+type DataExportService struct {
+}
+
+func NewDataExportService() *DataExportService {
+	return &DataExportService{}
+}
+
+// RequestExport enqueues building of the personal data archive for the given user.
+func (s *DataExportService) RequestExport(ctx context.Context, userId uint) error {
+	rabbitConn := contexthelper.GetRabbitConn(ctx)
+	if rabbitConn == nil {
+		return errors.New("no rabbit connection")
+	}
+	err := queue.DataExportTask.Publish(ctx, rabbitConn, queue.DataExportReportData{UserId: userId})
+	if err != nil {
+		return errors.Wrap(err, "enqueue data export task")
+	}
+	return nil
+}
+
+// GetExportFilePath verifies the signed download link and returns the path of the archive.
+func (s *DataExportService) GetExportFilePath(ctx context.Context, fileName string, query url.Values) (string, error) {
+	if !queue.IsDataExportFileName(fileName) {
+		return "", ErrDataExportNotFound
+	}
+	cfg := contexthelper.GetConfig(ctx)
+	if err := signedlink.Verify(cfg.Token.JwtSecret, fileName, query); err != nil {
+		return "", errors.Wrap(ErrDataExportNotFound, err.Error())
+	}
+	path := filepath.Join(cfg.DataExport.StorageDir, fileName)
+	if _, err := os.Stat(path); err != nil {
+		return "", errors.Wrap(ErrDataExportNotFound, err.Error())
+	}
+	return path, nil
+}
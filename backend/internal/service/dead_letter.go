@@ -0,0 +1,60 @@
+package service
+
+import (
+	"backend/internal/contexthelper"
+	"backend/internal/queue"
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+const defaultDeadLetterListLimit = 50
+
+var ErrUnknownTaskQueue = errors.New("unknown task queue")
+
+type DeadLetterService struct {
+}
+
+func NewDeadLetterService() *DeadLetterService {
+	return &DeadLetterService{}
+}
+
+func (s *DeadLetterService) List(ctx context.Context, queueName string, limit int) ([]queue.DeadLetter, error) {
+	q, ok := queue.GetTaskQueue(queueName)
+	if !ok {
+		return nil, ErrUnknownTaskQueue
+	}
+	if limit <= 0 {
+		limit = defaultDeadLetterListLimit
+	}
+	list, err := queue.ListDeadLetters(ctx, contexthelper.GetRabbitConn(ctx), q, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "list dead letters")
+	}
+	return list, nil
+}
+
+// Replay moves the selected dead letters (all when ids is empty) back to the main queue.
+func (s *DeadLetterService) Replay(ctx context.Context, queueName string, ids []string) (int, error) {
+	q, ok := queue.GetTaskQueue(queueName)
+	if !ok {
+		return 0, ErrUnknownTaskQueue
+	}
+	replayed, err := queue.ReplayDeadLetters(ctx, contexthelper.GetRabbitConn(ctx), q, ids)
+	if err != nil {
+		return replayed, errors.Wrap(err, "replay dead letters")
+	}
+	return replayed, nil
+}
+
+func (s *DeadLetterService) Purge(ctx context.Context, queueName string) (int, error) {
+	q, ok := queue.GetTaskQueue(queueName)
+	if !ok {
+		return 0, ErrUnknownTaskQueue
+	}
+	purged, err := queue.PurgeDeadLetters(ctx, contexthelper.GetRabbitConn(ctx), q)
+	if err != nil {
+		return 0, errors.Wrap(err, "purge dead letters")
+	}
+	return purged, nil
+}
@@ -0,0 +1,23 @@
+package service_test
+
+import (
+	"backend/internal/service"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDeadLetterService_UnknownQueue(t *testing.T) {
+	deadLetterService := service.NewDeadLetterService()
+	ctx := context.Background()
+
+	if _, err := deadLetterService.List(ctx, "unknown", 10); !errors.Is(err, service.ErrUnknownTaskQueue) {
+		t.Errorf("List: expected unknown queue error, got %v", err)
+	}
+	if _, err := deadLetterService.Replay(ctx, "unknown", nil); !errors.Is(err, service.ErrUnknownTaskQueue) {
+		t.Errorf("Replay: expected unknown queue error, got %v", err)
+	}
+	if _, err := deadLetterService.Purge(ctx, "unknown"); !errors.Is(err, service.ErrUnknownTaskQueue) {
+		t.Errorf("Purge: expected unknown queue error, got %v", err)
+	}
+}
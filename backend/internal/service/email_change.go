@@ -8,6 +8,7 @@ import (
 	"backend/internal/repository"
 	"backend/pkg/logger"
 	"context"
+	"database/sql"
 	"encoding/json"
 	"strings"
 )
@@ -52,7 +53,7 @@ func (s *Email) ChangeEmail(ctx context.Context, newEmail string) error {
 		return err
 	}
 	if exists {
-		logger.Info("Email change token already exists for email: %s", lowercaseEmail)
+		logger.Info("Email change token already exists for email: %s", logger.Email(lowercaseEmail))
 		return apperrors.NewEmailChangeEmailAlreadyUsedError("Email already exists")
 	}
 
@@ -70,7 +71,7 @@ func (s *Email) ChangeEmail(ctx context.Context, newEmail string) error {
 		return err
 	}
 	rabbitConn := contexthelper.GetRabbitConn(ctx)
-	err = queue.PublishEmailChangeTask(ctx, rabbitConn, confirmationToken)
+	err = queue.EmailChangeEmailTask.Publish(ctx, rabbitConn, queue.EmailChangeEmailData{EmailChangeToken: confirmationToken})
 	if err != nil {
 		logger.ErrorCtx(ctx, "Failed to enqueue email task: %v", err)
 		// Nie przerywamy rejestracji – można uznać, że task się nie udał, ale user się zarejestrował
@@ -78,3 +79,25 @@ func (s *Email) ChangeEmail(ctx context.Context, newEmail string) error {
 
 	return nil
 }
+
+// ResendEmailChange sends the confirmation email of the pending email change of the current
+// user again.
+func (s *Email) ResendEmailChange(ctx context.Context) error {
+	userId, ok := contexthelper.GetUserId(ctx)
+	if !ok {
+		return apperrors.NewGeneralCustomError("User not authenticated")
+	}
+	ct, err := s.confirmationTokenRepo.GetResendableEmailChangeToken(ctx, userId)
+	if err == sql.ErrNoRows {
+		return apperrors.NewConfirmationResendNotFoundError("No pending email change")
+	}
+	if err != nil {
+		return err
+	}
+	confirmationToken, err := resendToken(ctx, s.confirmationTokenRepo, ct)
+	if err != nil {
+		return err
+	}
+	rabbitConn := contexthelper.GetRabbitConn(ctx)
+	return queue.EmailChangeEmailTask.Publish(ctx, rabbitConn, queue.EmailChangeEmailData{EmailChangeToken: confirmationToken})
+}
@@ -46,7 +46,7 @@ func (s *PasswordService) ResetPassword(ctx context.Context, email string) error
 		return err
 	}
 	rabbitConn := contexthelper.GetRabbitConn(ctx)
-	err = queue.PublishPasswordResetTask(ctx, rabbitConn, confirmationToken)
+	err = queue.PasswordResetEmailTask.Publish(ctx, rabbitConn, queue.PasswordResetEmailData{PasswordResetToken: confirmationToken})
 	if err != nil {
 		logger.ErrorCtx(ctx, "Failed to enqueue password reset task: %v", err)
 	}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"context"
+	"database/sql"
 	"encoding/json"
 	"strings"
 
@@ -57,7 +58,7 @@ func (s *RegisterService) RegisterUser(ctx context.Context, userName, email, pas
 		return err
 	}
 	if exists {
-		logger.Info("Register token already exists for user: %s or email: %s", userName, lowercaseEmail)
+		logger.Info("Register token already exists for user: %s or email: %s", userName, logger.Email(lowercaseEmail))
 		return apperrors.NewRegisterUserNameOrEmailTakenError("Register token already exists")
 	}
 
@@ -100,7 +101,7 @@ func (s *RegisterService) RegisterUser(ctx context.Context, userName, email, pas
 		return err
 	}
 	rabbitConn := contexthelper.GetRabbitConn(ctx)
-	err = queue.PublishRegisterEmailTask(ctx, rabbitConn, registerToken)
+	err = queue.WelcomeEmailTask.Publish(ctx, rabbitConn, queue.WelcomeEmailData{RegisterToken: registerToken})
 	if err != nil {
 		logger.ErrorCtx(ctx, "Failed to enqueue email task: %v", err)
 		// Nie przerywamy rejestracji – można uznać, że task się nie udał, ale user się zarejestrował
@@ -108,3 +109,21 @@ func (s *RegisterService) RegisterUser(ctx context.Context, userName, email, pas
 
 	return nil
 }
+
+// ResendConfirmation sends the welcome email of the pending registration of email again.
+func (s *RegisterService) ResendConfirmation(ctx context.Context, email string) error {
+	lowercaseEmail := strings.ToLower(email)
+	ct, err := s.confirmationTokenRepo.GetResendableRegisterToken(ctx, lowercaseEmail)
+	if err == sql.ErrNoRows {
+		return apperrors.NewConfirmationResendNotFoundError("No pending registration")
+	}
+	if err != nil {
+		return err
+	}
+	registerToken, err := resendToken(ctx, s.confirmationTokenRepo, ct)
+	if err != nil {
+		return err
+	}
+	rabbitConn := contexthelper.GetRabbitConn(ctx)
+	return queue.WelcomeEmailTask.Publish(ctx, rabbitConn, queue.WelcomeEmailData{RegisterToken: registerToken})
+}
@@ -0,0 +1,94 @@
+package service_test
+
+import (
+	"backend/config"
+	"backend/internal/apperrors"
+	"backend/internal/contexthelper"
+	"backend/internal/repository"
+	"backend/internal/service"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newTestRegisterService(db *sql.DB) *service.RegisterService {
+	return service.NewRegisterService(repository.NewConfirmationTokenRepository(db), repository.NewUserRepository(db), repository.NewLanguageRepository(db))
+}
+
+func TestRegisterService_RegisterUser_PendingTokenBlocks(t *testing.T) {
+	ctx := contexthelper.SetConfig(context.Background(), &config.Config{DefaultLanguage: "en"})
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT id FROM users WHERE email").WillReturnError(sql.ErrNoRows)
+	// Only tokens that have not expired yet block the registration
+	mock.ExpectQuery(`SELECT 1 FROM confirmation_tokens .*expires_at > NOW\(\)`).
+		WithArgs("testuser", "test@example.com", "test@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+
+	err = newTestRegisterService(db).RegisterUser(ctx, "testuser", "test@example.com", "Test123!@#", "en")
+
+	if !apperrors.IsRegisterUserNameOrEmailTakenError(err) {
+		t.Errorf("expected username or email taken error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRegisterService_ResendConfirmation_NotFound(t *testing.T) {
+	ctx := contexthelper.SetConfig(context.Background(), &config.Config{})
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT .* FROM confirmation_tokens WHERE type = \?`).
+		WithArgs("register", "test@example.com").
+		WillReturnError(sql.ErrNoRows)
+
+	err = newTestRegisterService(db).ResendConfirmation(ctx, "Test@Example.com")
+
+	if !apperrors.IsConfirmationResendNotFoundError(err) {
+		t.Errorf("expected resend not found error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRegisterService_ResendConfirmation_TooManyRequests(t *testing.T) {
+	cfg := &config.Config{ConfirmationTokens: config.ConfirmationTokensConfig{ResendCooldownSeconds: 120, ResendMax: 5}}
+	ctx := contexthelper.SetConfig(context.Background(), cfg)
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT .* FROM confirmation_tokens WHERE type = \?`).
+		WithArgs("register", "test@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "token", "user_id", "type", "payload", "status", "expires_at", "status_changed_at", "created_at"}).
+			AddRow(7, "token123", 0, "register", "{}", "NEW", now.Add(time.Hour), now, now))
+	// The cooldown and the limit are checked by the update, which changes nothing here
+	mock.ExpectExec(`UPDATE confirmation_tokens SET token = \?, resend_count = resend_count \+ 1`).
+		WithArgs(sqlmock.AnyArg(), 7, "NEW", 5, int64(120)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = newTestRegisterService(db).ResendConfirmation(ctx, "test@example.com")
+
+	if !apperrors.IsConfirmationResendTooManyRequestsError(err) {
+		t.Errorf("expected resend too many requests error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"backend/internal/apperrors"
+	"backend/internal/contexthelper"
+	"backend/internal/models"
+	"backend/internal/queue"
+	"backend/internal/report"
+	"backend/internal/repository"
+	"backend/pkg/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+type ReportService struct {
+	reportRepo *repository.ReportRepository
+	userRepo   *repository.UserRepository
+}
+
+func NewReportService(rRepo *repository.ReportRepository, uRepo *repository.UserRepository) *ReportService {
+	return &ReportService{
+		reportRepo: rRepo,
+		userRepo:   uRepo,
+	}
+}
+
+// RequestReport stores a queued report and enqueues its generation. Only users listed in
+// the reports configuration are allowed to request reports.
+func (s *ReportService) RequestReport(ctx context.Context, userId uint, reportType, format string, params report.Params) (uint, error) {
+	user, err := s.userRepo.GetById(ctx, userId)
+	if err != nil {
+		return 0, errors.Wrap(err, "user not found")
+	}
+	cfg := contexthelper.GetConfig(ctx)
+	if !cfg.Reports.CanRequest(user.Email) {
+		return 0, apperrors.NewReportForbiddenError("User is not allowed to request reports")
+	}
+
+	jsonParams, err := json.Marshal(params)
+	if err != nil {
+		return 0, err
+	}
+	reportId, err := s.reportRepo.Create(ctx, user.Id, reportType, format, jsonParams)
+	if err != nil {
+		return 0, err
+	}
+
+	rabbitConn := contexthelper.GetRabbitConn(ctx)
+	if rabbitConn == nil {
+		err = errors.New("no rabbit connection")
+	} else {
+		err = queue.GenerateReportTask.Publish(ctx, rabbitConn, queue.ReportTask{ReportId: reportId})
+	}
+	if err != nil {
+		if markErr := s.reportRepo.MarkFailed(ctx, reportId, "failed to enqueue report"); markErr != nil {
+			logger.ErrorCtx(ctx, "Failed to mark report %d as failed: %v", reportId, markErr)
+		}
+		return 0, errors.Wrap(err, "enqueue report task")
+	}
+	return reportId, nil
+}
+
+func (s *ReportService) GetReport(ctx context.Context, userId, reportId uint) (models.Report, error) {
+	rep, err := s.reportRepo.GetByIdAndUserId(ctx, reportId, userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Report{}, apperrors.NewReportNotFoundError("Report not found")
+		}
+		return models.Report{}, err
+	}
+	return rep, nil
+}
+
+// GetReportFile returns the generated report when its output is ready for download.
+func (s *ReportService) GetReportFile(ctx context.Context, userId, reportId uint) (models.Report, error) {
+	rep, err := s.GetReport(ctx, userId, reportId)
+	if err != nil {
+		return models.Report{}, err
+	}
+	if rep.Status != models.ReportStatusDone || rep.OutputPath == "" {
+		return models.Report{}, apperrors.NewReportNotReadyError("Report is not ready")
+	}
+	return rep, nil
+}
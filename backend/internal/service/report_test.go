@@ -0,0 +1,120 @@
+package service_test
+
+import (
+	"backend/config"
+	"backend/internal/apperrors"
+	"backend/internal/contexthelper"
+	"backend/internal/report"
+	"backend/internal/repository"
+	"backend/internal/service"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var reportColumns = []string{"id", "user_id", "type", "format", "params", "status", "output_path", "error", "created_at", "started_at", "finished_at"}
+
+func TestReportService_RequestReport_Forbidden(t *testing.T) {
+	ctx := contexthelper.SetConfig(context.Background(), &config.Config{
+		Reports: config.ReportsConfig{AllowedEmails: []string{"analyst@example.com"}},
+	})
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	regTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT.*FROM users WHERE id").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name", "email", "password", "registered_at", "confirmed_at"}).
+			AddRow(1, "testuser", "test@example.com", "hash", regTime, regTime),
+	)
+
+	reportService := service.NewReportService(repository.NewReportRepository(db), repository.NewUserRepository(db))
+	_, err = reportService.RequestReport(ctx, 1, report.TypeUserRegistrations, "csv", report.Params{})
+
+	if !apperrors.IsReportForbiddenError(err) {
+		t.Errorf("expected forbidden error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestReportService_RequestReport_EnqueueFailure(t *testing.T) {
+	ctx := contexthelper.SetConfig(context.Background(), &config.Config{
+		Reports: config.ReportsConfig{AllowedEmails: []string{"Analyst@Example.com"}},
+	})
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	regTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT.*FROM users WHERE id").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name", "email", "password", "registered_at", "confirmed_at"}).
+			AddRow(1, "analyst", "analyst@example.com", "hash", regTime, regTime),
+	)
+	mock.ExpectExec("INSERT INTO reports").WillReturnResult(sqlmock.NewResult(7, 1))
+	// No rabbit connection in context - the report is marked as failed
+	mock.ExpectExec("UPDATE reports SET status").
+		WithArgs("failed", sqlmock.AnyArg(), 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	reportService := service.NewReportService(repository.NewReportRepository(db), repository.NewUserRepository(db))
+	_, err = reportService.RequestReport(ctx, 1, report.TypeUserRegistrations, "csv", report.Params{})
+
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestReportService_GetReport_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT.*FROM reports WHERE id").WillReturnError(sql.ErrNoRows)
+
+	reportService := service.NewReportService(repository.NewReportRepository(db), repository.NewUserRepository(db))
+	_, err = reportService.GetReport(context.Background(), 1, 5)
+
+	if !apperrors.IsReportNotFoundError(err) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestReportService_GetReportFile_NotReady(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT.*FROM reports WHERE id").WillReturnRows(
+		sqlmock.NewRows(reportColumns).
+			AddRow(5, 1, "user_sessions", "xlsx", "{}", "failed", nil, "boom", time.Now(), time.Now(), time.Now()),
+	)
+
+	reportService := service.NewReportService(repository.NewReportRepository(db), repository.NewUserRepository(db))
+	_, err = reportService.GetReportFile(context.Background(), 1, 5)
+
+	if !apperrors.IsReportNotReadyError(err) {
+		t.Errorf("expected not ready error, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
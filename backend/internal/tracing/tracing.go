@@ -0,0 +1,103 @@
+package tracing
+
+import (
+	"backend/config"
+	"backend/pkg/logger"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters of TracingConfig.
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+const (
+	tracerName      = "backend"
+	shutdownTimeout = 5 * time.Second
+)
+
+// Init installs the global tracer provider of the service ("webserver" or "consumer")
+// and the W3C trace context propagator. The propagator is installed even when tracing is
+// disabled, so the trace of an upstream proxy still reaches the consumer. The returned
+// function flushes the spans that are not exported yet.
+func Init(ctx context.Context, cfg *config.Config, serviceName string) (func(), error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if !cfg.Tracing.Enabled {
+		return func() {}, nil
+	}
+
+	exporter, err := newExporter(ctx, cfg.Tracing)
+	if err != nil {
+		return nil, fmt.Errorf("create %s trace exporter: %w", cfg.Tracing.Exporter, err)
+	}
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(
+			semconv.ServiceName(serviceName),
+			semconv.ServiceNamespace(cfg.AppName),
+			semconv.DeploymentEnvironment(cfg.AppEnv),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create trace resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Tracing.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	logger.Info("Tracing enabled: %s exporter, sample ratio %v", cfg.Tracing.Exporter, cfg.Tracing.SampleRatio)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			logger.Error("Failed to flush traces: %v", err)
+		}
+	}, nil
+}
+
+func newExporter(ctx context.Context, cfg config.TracingConfig) (sdktrace.SpanExporter, error) {
+	if cfg.Exporter == ExporterStdout {
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	}
+	var options []otlptracehttp.Option
+	if cfg.Endpoint != "" {
+		options = append(options, otlptracehttp.WithEndpoint(cfg.Endpoint))
+	}
+	if cfg.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	return otlptracehttp.New(ctx, options...)
+}
+
+// Tracer returns the tracer of the application. Spans are not recorded until Init
+// enables tracing.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// End records err on the span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
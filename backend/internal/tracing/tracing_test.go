@@ -0,0 +1,78 @@
+package tracing_test
+
+import (
+	"backend/config"
+	"backend/internal/repository"
+	"backend/internal/tracing"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+	return recorder
+}
+
+func TestRepositoryQuerySpan(t *testing.T) {
+	recorder := recordSpans(t)
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	mock.ExpectQuery("SELECT id, name, email, password, registered_at, confirmed_at").WithArgs("test@example.com").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name", "email", "password", "registered_at", "confirmed_at"}).AddRow(1, "test", "test@example.com", "hash", time.Now(), time.Now()),
+	)
+
+	ctx, parent := tracing.Tracer().Start(context.Background(), "POST /login")
+	if _, err := repository.NewUserRepository(db).GetByEmail(ctx, "test@example.com"); err != nil {
+		t.Fatalf("GetByEmail failed: %v", err)
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "UserRepository.GetByEmail" {
+		t.Errorf("expected the repository method as span name, got %q", span.Name())
+	}
+	if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("expected the query span to be a child of the request span")
+	}
+	for _, attr := range span.Attributes() {
+		if strings.Contains(attr.Value.Emit(), "test@example.com") {
+			t.Errorf("expected no query arguments in the span, got %s=%s", attr.Key, attr.Value.Emit())
+		}
+		if attr.Key == "db.query.text" && !strings.HasPrefix(attr.Value.AsString(), "SELECT id, name, email") {
+			t.Errorf("unexpected query text %q", attr.Value.AsString())
+		}
+	}
+}
+
+func TestInit_Disabled(t *testing.T) {
+	shutdown, err := tracing.Init(context.Background(), &config.Config{}, "webserver")
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	defer shutdown()
+
+	if !slices.Contains(otel.GetTextMapPropagator().Fields(), "traceparent") {
+		t.Error("expected the trace context propagator to be installed")
+	}
+	_, span := tracing.Tracer().Start(context.Background(), "test")
+	defer span.End()
+	if span.IsRecording() {
+		t.Error("expected no spans to be recorded while tracing is disabled")
+	}
+}
@@ -36,3 +36,9 @@ func loadBundleMessageFile(bundle *i18n.Bundle, lang string) error {
 	}
 	return nil
 }
+
+// HasLanguage reports whether there are translations for the language code.
+func HasLanguage(lang string) bool {
+	_, err := localesFS.ReadFile("translates/" + lang + ".json")
+	return err == nil
+}
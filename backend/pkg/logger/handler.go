@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+)
+
+// handler adds the attributes of the context to the records and writes errors to a
+// separate output.
+type handler struct {
+	out          slog.Handler
+	errOut       slog.Handler
+	contextAttrs ContextAttrs
+}
+
+func newHandler(out, errOut io.Writer, format string, contextAttrs ContextAttrs) *handler {
+	options := &slog.HandlerOptions{AddSource: true, Level: level, ReplaceAttr: replaceAttr}
+	newBase := func(w io.Writer) slog.Handler {
+		if format == FormatJSON {
+			return slog.NewJSONHandler(w, options)
+		}
+		return slog.NewTextHandler(w, options)
+	}
+	return &handler{out: newBase(out), errOut: newBase(errOut), contextAttrs: contextAttrs}
+}
+
+func (h *handler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= level.Level()
+}
+
+func (h *handler) Handle(ctx context.Context, record slog.Record) error {
+	if h.contextAttrs != nil {
+		record = record.Clone()
+		record.AddAttrs(h.contextAttrs(ctx)...)
+	}
+	if record.Level >= slog.LevelError {
+		return h.errOut.Handle(ctx, record)
+	}
+	return h.out.Handle(ctx, record)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{out: h.out.WithAttrs(attrs), errOut: h.errOut.WithAttrs(attrs), contextAttrs: h.contextAttrs}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{out: h.out.WithGroup(name), errOut: h.errOut.WithGroup(name), contextAttrs: h.contextAttrs}
+}
+
+// replaceAttr masks secrets, logs times in UTC, the source as "package/file.go:line" and
+// LevelFatal as FATAL.
+func replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return redactAttr(a)
+	}
+	switch a.Key {
+	case slog.TimeKey:
+		if a.Value.Kind() == slog.KindTime {
+			return slog.Time(slog.TimeKey, a.Value.Time().UTC())
+		}
+	case slog.LevelKey:
+		if l, ok := a.Value.Any().(slog.Level); ok && l >= LevelFatal {
+			return slog.String(slog.LevelKey, "FATAL")
+		}
+	case slog.SourceKey:
+		if source, ok := a.Value.Any().(*slog.Source); ok {
+			if source.File == "" {
+				// Records of the log package have no caller
+				return slog.Attr{}
+			}
+			file := filepath.Join(filepath.Base(filepath.Dir(source.File)), filepath.Base(source.File))
+			return slog.String(slog.SourceKey, file+":"+strconv.Itoa(source.Line))
+		}
+	}
+	return redactAttr(a)
+}
@@ -2,93 +2,128 @@ package logger
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
 	"os"
+	"runtime"
+	"strings"
+	"time"
 )
 
-type requestIdGetter = func (ctx context.Context) string
-var (
-	infoLogger  = log.New(&callerAwareWriter{"info", os.Stdout}, "", 0)
-	errorLogger = log.New(&callerAwareWriter{"error", os.Stderr}, "", 0)
-	debugLogger = log.New(&callerAwareWriter{"debug", os.Stdout}, "", 0)
-	fatalLogger = log.New(&callerAwareWriter{"fatal", os.Stdout}, "", 0)
-	warnLogger  = log.New(&callerAwareWriter{"warn", os.Stdout}, "", 0)
-
-	logLevel = "info" // default
-	getRequestId requestIdGetter
+// ContextAttrs returns the attributes of a context added to every record logged with it,
+// e.g. the request ID.
+type ContextAttrs = func(ctx context.Context) []slog.Attr
+
+// Output formats of Init.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
 )
 
-const RequestIdLogPrefix = "reqID:"
+// LevelFatal is the level of Fatal, above slog.LevelError.
+const LevelFatal = slog.Level(12)
+
+var (
+	level         = new(slog.LevelVar)
+	defaultLogger = slog.New(newHandler(os.Stdout, os.Stderr, FormatText, nil))
+)
 
-func Init(level string, rqIdGetter requestIdGetter) {
-	logLevel = level
-	infoLogger.Println("Logger initialized with level:", level)
-	getRequestId = rqIdGetter
+// Init sets the minimum level ("debug", "info", "warn" or "error"), the output format
+// (FormatText or FormatJSON) and the attributes taken from the context of the *Ctx
+// functions. Records of level error and above go to stderr, the rest to stdout. The
+// standard log package and slog.Default write through the same handler.
+func Init(levelName, format string, contextAttrs ContextAttrs) {
+	level.Set(ParseLevel(levelName))
+	defaultLogger = slog.New(newHandler(os.Stdout, os.Stderr, format, contextAttrs))
+	slog.SetDefault(defaultLogger)
+	Info("Logger initialized with level: %s, format: %s", levelName, format)
 }
 
-func Info(msg string, args ...any) {
-	if logLevel == "info" || logLevel == "debug" {
-		infoLogger.Printf(msg, args...)
+// ParseLevel returns the slog level of a level name, info when it is unknown.
+func ParseLevel(name string) slog.Level {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
 	}
+	return slog.LevelInfo
 }
 
-func Error(msg string, args ...any) {
-	errorLogger.Printf(msg, args...)
+// Log writes msg with structured attributes, e.g.
+// logger.Log(ctx, slog.LevelInfo, "Request handled", slog.Int("status", 200)).
+func Log(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	write(ctx, level, msg, attrs)
 }
 
+// The functions below format msg with args like fmt.Printf, with the known secret fields
+// of structs masked. The *Ctx variants add the attributes of the context.
+
 func Debug(msg string, args ...any) {
-	if logLevel == "debug" {
-		debugLogger.Printf(msg, args...)
-	}
+	write(context.Background(), slog.LevelDebug, sprintf(msg, args), nil)
 }
-func Fatal(msg string, args ...any) {
-	fatalLogger.Fatalf(msg, args...)
+
+func Info(msg string, args ...any) {
+	write(context.Background(), slog.LevelInfo, sprintf(msg, args), nil)
 }
+
 func Warn(msg string, args ...any) {
-	if logLevel == "warn" || logLevel == "debug" || logLevel == "info" {
-		warnLogger.Printf(msg, args...)
-	}
+	write(context.Background(), slog.LevelWarn, sprintf(msg, args), nil)
 }
 
-// --- nowe funkcje z contextem ---
-func InfoCtx(ctx context.Context, msg string, args ...any) {
-	if logLevel == "info" || logLevel == "debug" {
-		prefix := prefixFromContext(ctx)
-		infoLogger.Printf(prefix+msg, args...)
-	}
+func Error(msg string, args ...any) {
+	write(context.Background(), slog.LevelError, sprintf(msg, args), nil)
 }
 
-func ErrorCtx(ctx context.Context, msg string, args ...any) {
-	prefix := prefixFromContext(ctx)
-	errorLogger.Printf(prefix+msg, args...)
+// Fatal logs the message and exits with status 1.
+func Fatal(msg string, args ...any) {
+	write(context.Background(), LevelFatal, sprintf(msg, args), nil)
+	os.Exit(1)
 }
 
 func DebugCtx(ctx context.Context, msg string, args ...any) {
-	if logLevel == "debug" {
-		prefix := prefixFromContext(ctx)
-		debugLogger.Printf(prefix+msg, args...)
-	}
+	write(ctx, slog.LevelDebug, sprintf(msg, args), nil)
 }
 
-func FatalCtx(ctx context.Context, msg string, args ...any) {
-	prefix := prefixFromContext(ctx)
-	fatalLogger.Printf(prefix+msg, args...)
+func InfoCtx(ctx context.Context, msg string, args ...any) {
+	write(ctx, slog.LevelInfo, sprintf(msg, args), nil)
 }
 
 func WarnCtx(ctx context.Context, msg string, args ...any) {
-	if logLevel == "warn" || logLevel == "debug" || logLevel == "info" {
-		prefix := prefixFromContext(ctx)
-		warnLogger.Printf(prefix+msg, args...)
+	write(ctx, slog.LevelWarn, sprintf(msg, args), nil)
+}
+
+func ErrorCtx(ctx context.Context, msg string, args ...any) {
+	write(ctx, slog.LevelError, sprintf(msg, args), nil)
+}
+
+// FatalCtx logs the message and exits with status 1.
+func FatalCtx(ctx context.Context, msg string, args ...any) {
+	write(ctx, LevelFatal, sprintf(msg, args), nil)
+	os.Exit(1)
+}
+
+func sprintf(msg string, args []any) string {
+	if len(args) == 0 {
+		return msg
 	}
+	return fmt.Sprintf(msg, redactArgs(args)...)
 }
 
-func prefixFromContext(ctx context.Context) string {
-	if getRequestId == nil {
-		return ""
+// write logs the record with the caller of the exported function as its source.
+func write(ctx context.Context, level slog.Level, msg string, attrs []slog.Attr) {
+	if ctx == nil {
+		ctx = context.Background()
 	}
-	reqId := getRequestId(ctx)
-	if reqId != "" {
-		return "[" + RequestIdLogPrefix + " " + reqId + "] "
+	handler := defaultLogger.Handler()
+	if !handler.Enabled(ctx, level) {
+		return
 	}
-	return ""
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // skip Callers, write and the exported function
+	record := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	record.AddAttrs(attrs...)
+	_ = handler.Handle(ctx, record)
 }
@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type requestIdKey struct{}
+
+func captureJSON(t *testing.T, levelName string) (out, errOut *bytes.Buffer) {
+	t.Helper()
+	out, errOut = &bytes.Buffer{}, &bytes.Buffer{}
+	previousLogger, previousLevel := defaultLogger, level.Level()
+	t.Cleanup(func() {
+		defaultLogger = previousLogger
+		level.Set(previousLevel)
+	})
+	level.Set(ParseLevel(levelName))
+	defaultLogger = slog.New(newHandler(out, errOut, FormatJSON, func(ctx context.Context) []slog.Attr {
+		if id, ok := ctx.Value(requestIdKey{}).(string); ok {
+			return []slog.Attr{slog.String("request_id", id)}
+		}
+		return nil
+	}))
+	return out, errOut
+}
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestInfoCtx_JSONWithContextAttrs(t *testing.T) {
+	out, _ := captureJSON(t, "info")
+	ctx := context.WithValue(context.Background(), requestIdKey{}, "req-1")
+
+	InfoCtx(ctx, "User %d logged in", 7)
+
+	records := decodeLines(t, out)
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	record := records[0]
+	if record["msg"] != "User 7 logged in" || record["level"] != "INFO" || record["request_id"] != "req-1" {
+		t.Errorf("unexpected record: %v", record)
+	}
+	if source, _ := record["source"].(string); !strings.HasPrefix(source, "logger/logger_test.go:") {
+		t.Errorf("expected the caller as source, got %q", source)
+	}
+}
+
+func TestLevels(t *testing.T) {
+	out, errOut := captureJSON(t, "error")
+
+	Debug("debug")
+	Info("info")
+	Warn("warn")
+	Error("error")
+
+	if out.Len() != 0 {
+		t.Errorf("expected nothing below error level, got %s", out.String())
+	}
+	records := decodeLines(t, errOut)
+	if len(records) != 1 || records[0]["msg"] != "error" {
+		t.Errorf("expected only the error on stderr, got %v", records)
+	}
+}
+
+func TestLog_Attributes(t *testing.T) {
+	out, _ := captureJSON(t, "debug")
+
+	Log(context.Background(), slog.LevelInfo, "Request handled", slog.String("route", "/reports/{id}"), slog.Int("status", 404))
+
+	records := decodeLines(t, out)
+	if len(records) != 1 || records[0]["route"] != "/reports/{id}" || records[0]["status"] != float64(404) {
+		t.Errorf("unexpected records: %v", records)
+	}
+}
+
+func TestInfo_KeepsPercentWithoutArgs(t *testing.T) {
+	out, _ := captureJSON(t, "info")
+
+	Info("GET /search?q=100%")
+
+	records := decodeLines(t, out)
+	if len(records) != 1 || records[0]["msg"] != "GET /search?q=100%" {
+		t.Errorf("unexpected records: %v", records)
+	}
+}
@@ -0,0 +1,213 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+// Redacted replaces secrets in the output.
+const Redacted = "[REDACTED]"
+
+// Fields masked wherever they are logged: as attributes, as fields of structs passed to
+// the printf functions and as attributes of groups. Names are compared in lower case
+// without underscores and dashes, so "refresh_token" matches the RefreshToken field.
+var (
+	secretFields = map[string]bool{
+		"password":        true,
+		"currentpassword": true,
+		"newpassword":     true,
+		"oldpassword":     true,
+		"token":           true,
+		"accesstoken":     true,
+		"refreshtoken":    true,
+		"jwt":             true,
+		"jwtsecret":       true,
+		"secret":          true,
+		"apikey":          true,
+		"authorization":   true,
+		"cookie":          true,
+	}
+	emailFields = map[string]bool{
+		"email":    true,
+		"newemail": true,
+		"oldemail": true,
+	}
+)
+
+// secret is a value logged as Redacted.
+type secret struct {
+	v any
+}
+
+// Secret wraps a value that must not appear in the logs, like a token or a password.
+// An empty string is logged as is, so a missing token can still be told apart.
+func Secret(v any) any {
+	return secret{v: v}
+}
+
+func (s secret) String() string {
+	if s.v == nil || s.v == "" {
+		return ""
+	}
+	return Redacted
+}
+
+func (s secret) Format(f fmt.State, verb rune) {
+	formatString(f, verb, s.String())
+}
+
+func (s secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+// email is an email address logged with the local part masked.
+type email string
+
+// Email wraps an email address so only its first character and domain are logged, e.g.
+// "j***@example.com".
+func Email(v string) any {
+	return email(v)
+}
+
+func (e email) String() string {
+	return maskEmail(string(e))
+}
+
+func (e email) Format(f fmt.State, verb rune) {
+	formatString(f, verb, e.String())
+}
+
+func (e email) LogValue() slog.Value {
+	return slog.StringValue(e.String())
+}
+
+func maskEmail(address string) string {
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		if address == "" {
+			return ""
+		}
+		return Redacted
+	}
+	if at == 0 {
+		return "***" + address[at:]
+	}
+	return address[:1] + "***" + address[at:]
+}
+
+func formatString(f fmt.State, verb rune, s string) {
+	if verb == 'q' {
+		fmt.Fprintf(f, "%q", s)
+		return
+	}
+	io.WriteString(f, s)
+}
+
+func normalizeField(name string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+}
+
+// redactField returns the value of a field with a known name masked.
+func redactField(name string, v any) (any, bool) {
+	field := normalizeField(name)
+	switch {
+	case secretFields[field]:
+		return Secret(v), true
+	case emailFields[field]:
+		if address, ok := v.(string); ok {
+			return Email(address), true
+		}
+	}
+	return v, false
+}
+
+// redactAttr masks an attribute with a known key and the known fields of structs.
+func redactAttr(a slog.Attr) slog.Attr {
+	if v, ok := redactField(a.Key, a.Value.Any()); ok {
+		return slog.Any(a.Key, v.(slog.LogValuer).LogValue())
+	}
+	if a.Value.Kind() == slog.KindAny {
+		if s, ok := redactArg(a.Value.Any()).(redactedStruct); ok {
+			return slog.Attr{Key: a.Key, Value: s.LogValue()}
+		}
+	}
+	return a
+}
+
+// redactArgs masks the known fields of structs passed to the printf functions.
+func redactArgs(args []any) []any {
+	redacted := make([]any, len(args))
+	for i, arg := range args {
+		redacted[i] = redactArg(arg)
+	}
+	return redacted
+}
+
+type redactedField struct {
+	name  string
+	value any
+}
+
+// redactedStruct is a struct with its known fields masked. It is printed like %+v.
+type redactedStruct struct {
+	pointer bool
+	fields  []redactedField
+}
+
+// redactArg returns a struct, or a pointer to one, as a redactedStruct. Other values and
+// types with their own formatting, like errors and times, are returned unchanged.
+func redactArg(arg any) any {
+	switch arg.(type) {
+	case nil, error, fmt.Stringer, fmt.Formatter, slog.LogValuer:
+		return arg
+	}
+	v := reflect.ValueOf(arg)
+	pointer := v.Kind() == reflect.Pointer
+	if pointer {
+		if v.IsNil() {
+			return arg
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return arg
+	}
+	s := redactedStruct{pointer: pointer}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		value, ok := redactField(field.Name, v.Field(i).Interface())
+		if !ok {
+			value = redactArg(value)
+		}
+		s.fields = append(s.fields, redactedField{name: field.Name, value: value})
+	}
+	return s
+}
+
+func (s redactedStruct) Format(f fmt.State, _ rune) {
+	if s.pointer {
+		io.WriteString(f, "&")
+	}
+	io.WriteString(f, "{")
+	for i, field := range s.fields {
+		if i > 0 {
+			io.WriteString(f, " ")
+		}
+		fmt.Fprintf(f, "%s:%v", field.name, field.value)
+	}
+	io.WriteString(f, "}")
+}
+
+func (s redactedStruct) LogValue() slog.Value {
+	attrs := make([]slog.Attr, len(s.fields))
+	for i, field := range s.fields {
+		attrs[i] = slog.Any(field.name, field.value)
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type loginRequest struct {
+	Email      string
+	Password   string
+	RememberMe bool
+}
+
+type session struct {
+	UserId       uint
+	RefreshToken string
+	Login        loginRequest
+}
+
+func TestSecretAndEmail(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		arg    any
+		want   string
+	}{
+		{"secret", "token: %s", Secret("eyJhbGciOi"), "token: " + Redacted},
+		{"secret quoted", "token: %q", Secret("eyJhbGciOi"), `token: "` + Redacted + `"`},
+		{"empty secret", "token: %q", Secret(""), `token: ""`},
+		{"email", "sent to %s", Email("john@example.com"), "sent to j***@example.com"},
+		{"invalid email", "sent to %v", Email("john"), "sent to " + Redacted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fmt.Sprintf(tt.format, tt.arg); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestInfo_MasksStructFields(t *testing.T) {
+	out, _ := captureJSON(t, "debug")
+
+	Debug("Login request: %v", loginRequest{Email: "john@example.com", Password: "Test123!@#", RememberMe: true})
+	Debug("Session: %v", &session{UserId: 7, RefreshToken: "abc", Login: loginRequest{Password: "Test123!@#"}})
+
+	records := decodeLines(t, out)
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if want := "Login request: {Email:j***@example.com Password:[REDACTED] RememberMe:true}"; records[0]["msg"] != want {
+		t.Errorf("expected %q, got %q", want, records[0]["msg"])
+	}
+	if want := "Session: &{UserId:7 RefreshToken:[REDACTED] Login:{Email: Password:[REDACTED] RememberMe:false}}"; records[1]["msg"] != want {
+		t.Errorf("expected %q, got %q", want, records[1]["msg"])
+	}
+}
+
+func TestLog_MasksAttributes(t *testing.T) {
+	out, _ := captureJSON(t, "debug")
+
+	Log(context.Background(), slog.LevelInfo, "Login",
+		slog.String("refresh_token", "abc"),
+		slog.String("email", "john@example.com"),
+		slog.Group("request", slog.String("password", "Test123!@#")),
+		slog.Any("session", session{UserId: 7, RefreshToken: "abc"}),
+	)
+
+	line := out.String()
+	for _, leaked := range []string{"abc", "john@", "Test123"} {
+		if strings.Contains(line, leaked) {
+			t.Errorf("expected %q to be masked, got %s", leaked, line)
+		}
+	}
+	records := decodeLines(t, out)
+	if len(records) != 1 || records[0]["email"] != "j***@example.com" || records[0]["refresh_token"] != Redacted {
+		t.Errorf("unexpected records: %v", records)
+	}
+	if group, _ := records[0]["session"].(map[string]any); group["UserId"] != float64(7) {
+		t.Errorf("expected the session as a group, got %v", records[0]["session"])
+	}
+}
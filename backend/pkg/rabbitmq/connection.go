@@ -0,0 +1,200 @@
+package rabbitmq
+
+import (
+	"backend/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	maxReconnectIntervalSeconds = 30
+	defaultPublisherPoolSize    = 4
+)
+
+var ErrNotConnected = errors.New("rabbitmq: not connected")
+
+// Connection wraps an AMQP connection and transparently re-establishes it after the
+// broker closes it. Channels opened by callers die with the underlying connection;
+// consumers should wait on Reconnected and open new ones.
+type Connection struct {
+	url string
+
+	mu          sync.RWMutex
+	conn        *amqp.Connection
+	reconnected chan struct{}
+	declared    map[string]bool
+	closing     bool
+
+	pool chan *amqp.Channel
+	done chan struct{}
+}
+
+// Dial connects to the broker, retrying with exponential backoff until ctx is done,
+// and starts watching the connection for unexpected closes.
+func Dial(ctx context.Context, url string) (*Connection, error) {
+	conn, err := dialWithRetry(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	c := &Connection{
+		url:         url,
+		conn:        conn,
+		reconnected: make(chan struct{}),
+		declared:    map[string]bool{},
+		pool:        make(chan *amqp.Channel, defaultPublisherPoolSize),
+		done:        make(chan struct{}),
+	}
+	go c.watch(conn)
+	return c, nil
+}
+
+// Channel opens a new channel on the current connection.
+func (c *Connection) Channel() (*amqp.Channel, error) {
+	if c == nil {
+		return nil, ErrNotConnected
+	}
+	c.mu.RLock()
+	conn := c.conn
+	c.mu.RUnlock()
+	if conn == nil || conn.IsClosed() {
+		return nil, ErrNotConnected
+	}
+	return conn.Channel()
+}
+
+// Reconnected returns a channel that is closed as soon as the connection has been
+// re-established after a failure.
+func (c *Connection) Reconnected() <-chan struct{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.reconnected
+}
+
+// Done returns a channel that is closed once Close has been called.
+func (c *Connection) Done() <-chan struct{} {
+	return c.done
+}
+
+// DeclareOnce runs declare on a fresh channel the first time key is used on the current
+// connection. Declarations are repeated after a reconnect.
+func (c *Connection) DeclareOnce(key string, declare func(ch *amqp.Channel) error) error {
+	if c == nil {
+		return ErrNotConnected
+	}
+	c.mu.RLock()
+	done := c.declared[key]
+	c.mu.RUnlock()
+	if done {
+		return nil
+	}
+	ch, err := c.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+	if err := declare(ch); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.declared[key] = true
+	c.mu.Unlock()
+	return nil
+}
+
+// Close closes the connection and stops reconnecting.
+func (c *Connection) Close() error {
+	c.mu.Lock()
+	if c.closing {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closing = true
+	conn := c.conn
+	c.mu.Unlock()
+
+	close(c.done)
+	c.drainPool()
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
+
+func (c *Connection) watch(conn *amqp.Connection) {
+	for {
+		closeErr, ok := <-conn.NotifyClose(make(chan *amqp.Error, 1))
+		c.mu.RLock()
+		closing := c.closing
+		c.mu.RUnlock()
+		if closing {
+			return
+		}
+		if ok && closeErr != nil {
+			logger.Warn("RabbitMQ connection closed: %v", closeErr)
+		} else {
+			logger.Warn("RabbitMQ connection closed")
+		}
+
+		newConn, err := dialWithRetry(context.Background(), c.url, c.done)
+		if err != nil {
+			logger.Info("RabbitMQ reconnect stopped: %v", err)
+			return
+		}
+		c.drainPool()
+
+		c.mu.Lock()
+		if c.closing {
+			c.mu.Unlock()
+			newConn.Close()
+			return
+		}
+		c.conn = newConn
+		c.declared = map[string]bool{}
+		close(c.reconnected)
+		c.reconnected = make(chan struct{})
+		c.mu.Unlock()
+
+		logger.Info("RabbitMQ connection re-established")
+		conn = newConn
+	}
+}
+
+func (c *Connection) drainPool() {
+	for {
+		select {
+		case ch := <-c.pool:
+			ch.Close()
+		default:
+			return
+		}
+	}
+}
+
+func dialWithRetry(ctx context.Context, url string, stop <-chan struct{}) (*amqp.Connection, error) {
+	var attempt int
+	for {
+		conn, err := amqp.Dial(url)
+		if err == nil {
+			logger.Info("Połączono z RabbitMQ")
+			return conn, nil
+		}
+
+		attempt++
+		backoff := time.Duration(math.Min(math.Pow(2, float64(attempt)), maxReconnectIntervalSeconds)) * time.Second
+		logger.Warn("Błąd połączenia z RabbitMQ (próba %d): %v. Ponawiam za %v", attempt, err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timeout podczas łączenia z RabbitMQ: %w", err)
+		case <-stop:
+			return nil, fmt.Errorf("connection closed while reconnecting: %w", err)
+		case <-time.After(backoff):
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package rabbitmq
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestDeclareOnce_RunsOncePerName(t *testing.T) {
+	c := dialFakeBroker(t, newFakeBroker(t, confirmAck))
+
+	calls := map[string]int{}
+	declare := func(name string) func(ch *amqp.Channel) error {
+		return func(ch *amqp.Channel) error {
+			calls[name]++
+			return nil
+		}
+	}
+	for _, name := range []string{"email", "email", "report", "email"} {
+		if err := c.DeclareOnce(name, declare(name)); err != nil {
+			t.Fatalf("DeclareOnce(%s) error = %v", name, err)
+		}
+	}
+
+	if calls["email"] != 1 || calls["report"] != 1 {
+		t.Errorf("declare calls = %v, want one per name", calls)
+	}
+}
+
+func TestDeclareOnce_RepeatsAfterReconnect(t *testing.T) {
+	broker := newFakeBroker(t, confirmAck)
+	c := dialFakeBroker(t, broker)
+
+	calls := 0
+	declare := func(ch *amqp.Channel) error {
+		calls++
+		return nil
+	}
+	if err := c.DeclareOnce("email", declare); err != nil {
+		t.Fatalf("DeclareOnce() error = %v", err)
+	}
+
+	reconnected := c.Reconnected()
+	broker.dropConnections()
+	select {
+	case <-reconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection was not re-established")
+	}
+
+	if err := c.DeclareOnce("email", declare); err != nil {
+		t.Fatalf("DeclareOnce() after reconnect error = %v", err)
+	}
+	if err := c.DeclareOnce("email", declare); err != nil {
+		t.Fatalf("DeclareOnce() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("declare calls = %d, want 2", calls)
+	}
+}
+
+func TestDeclareOnce_FailedDeclarationIsRepeated(t *testing.T) {
+	c := dialFakeBroker(t, newFakeBroker(t, confirmAck))
+
+	errDeclare := errors.New("declare failed")
+	calls := 0
+	declare := func(ch *amqp.Channel) error {
+		calls++
+		if calls == 1 {
+			return errDeclare
+		}
+		return nil
+	}
+	if err := c.DeclareOnce("email", declare); !errors.Is(err, errDeclare) {
+		t.Fatalf("DeclareOnce() error = %v, want %v", err, errDeclare)
+	}
+	if err := c.DeclareOnce("email", declare); err != nil {
+		t.Fatalf("DeclareOnce() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("declare calls = %d, want 2", calls)
+	}
+}
+
+func TestDeclareOnce_NotConnected(t *testing.T) {
+	var c *Connection
+	err := c.DeclareOnce("email", func(ch *amqp.Channel) error { return nil })
+	if !errors.Is(err, ErrNotConnected) {
+		t.Errorf("DeclareOnce() error = %v, want %v", err, ErrNotConnected)
+	}
+}
+
+// confirmMode is how the fake broker answers publishes on a channel in confirm mode.
+type confirmMode int
+
+const (
+	confirmAck confirmMode = iota
+	confirmNack
+	confirmNever
+)
+
+// fakeBroker speaks just enough AMQP 0-9-1 to open connections and channels, put them
+// in confirm mode and confirm publishes.
+type fakeBroker struct {
+	ln      net.Listener
+	confirm confirmMode
+
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+func newFakeBroker(t *testing.T, confirm confirmMode) *fakeBroker {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	b := &fakeBroker{ln: ln, confirm: confirm}
+	t.Cleanup(func() {
+		ln.Close()
+		b.dropConnections()
+	})
+	go b.accept()
+	return b
+}
+
+func dialFakeBroker(t *testing.T, b *fakeBroker) *Connection {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, err := Dial(ctx, "amqp://guest:guest@"+b.ln.Addr().String()+"/")
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// dropConnections closes the open connections without the closing handshake, as when
+// the broker goes away.
+func (b *fakeBroker) dropConnections() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, conn := range b.conns {
+		conn.Close()
+	}
+	b.conns = nil
+}
+
+func (b *fakeBroker) accept() {
+	for {
+		conn, err := b.ln.Accept()
+		if err != nil {
+			return
+		}
+		b.mu.Lock()
+		b.conns = append(b.conns, conn)
+		b.mu.Unlock()
+		go b.serve(conn)
+	}
+}
+
+func (b *fakeBroker) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	if _, err := io.ReadFull(r, make([]byte, 8)); err != nil {
+		return
+	}
+	start := []byte{0, 9}
+	start = binary.BigEndian.AppendUint32(start, 0)
+	start = appendLongString(start, "PLAIN")
+	start = appendLongString(start, "en_US")
+	writeMethod(conn, 0, 10, 10, start)
+
+	deliveryTags := map[uint16]uint64{}
+	for {
+		frameType, channel, payload, err := readFrame(r)
+		if err != nil {
+			return
+		}
+		if frameType != 1 || len(payload) < 4 {
+			continue
+		}
+		class, method := binary.BigEndian.Uint16(payload), binary.BigEndian.Uint16(payload[2:])
+		switch {
+		case class == 10 && method == 11: // connection.start-ok
+			tune := binary.BigEndian.AppendUint16(nil, 2047)
+			tune = binary.BigEndian.AppendUint32(tune, 131072)
+			tune = binary.BigEndian.AppendUint16(tune, 0)
+			writeMethod(conn, 0, 10, 30, tune)
+		case class == 10 && method == 40: // connection.open
+			writeMethod(conn, 0, 10, 41, []byte{0})
+		case class == 10 && method == 50: // connection.close
+			writeMethod(conn, 0, 10, 51, nil)
+			return
+		case class == 20 && method == 10: // channel.open
+			writeMethod(conn, channel, 20, 11, appendLongString(nil, ""))
+		case class == 20 && method == 40: // channel.close
+			writeMethod(conn, channel, 20, 41, nil)
+		case class == 85 && method == 10: // confirm.select
+			writeMethod(conn, channel, 85, 11, nil)
+		case class == 60 && method == 40: // basic.publish
+			deliveryTags[channel]++
+			confirmation := binary.BigEndian.AppendUint64(nil, deliveryTags[channel])
+			switch b.confirm {
+			case confirmAck:
+				writeMethod(conn, channel, 60, 80, append(confirmation, 0))
+			case confirmNack:
+				writeMethod(conn, channel, 60, 120, append(confirmation, 0))
+			}
+		}
+	}
+}
+
+func readFrame(r io.Reader) (frameType byte, channel uint16, payload []byte, err error) {
+	header := make([]byte, 7)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return 0, 0, nil, err
+	}
+	payload = make([]byte, binary.BigEndian.Uint32(header[3:])+1)
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return 0, 0, nil, err
+	}
+	return header[0], binary.BigEndian.Uint16(header[1:]), payload[:len(payload)-1], nil
+}
+
+func writeMethod(w io.Writer, channel, class, method uint16, args []byte) {
+	payload := binary.BigEndian.AppendUint16(nil, class)
+	payload = binary.BigEndian.AppendUint16(payload, method)
+	payload = append(payload, args...)
+
+	frame := []byte{1}
+	frame = binary.BigEndian.AppendUint16(frame, channel)
+	frame = binary.BigEndian.AppendUint32(frame, uint32(len(payload)))
+	frame = append(frame, payload...)
+	w.Write(append(frame, 0xCE))
+}
+
+func appendLongString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(s)))
+	return append(b, s...)
+}
@@ -0,0 +1,109 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DefaultPublishTimeout bounds a publish including the broker confirmation when the
+// caller's context has no earlier deadline.
+const DefaultPublishTimeout = 5 * time.Second
+
+var (
+	ErrPublishNacked  = errors.New("rabbitmq: message was nacked by the broker")
+	ErrConfirmTimeout = errors.New("rabbitmq: timed out waiting for publish confirmation")
+)
+
+// PublishError describes a failed publish. Err is one of ErrNotConnected,
+// ErrPublishNacked, ErrConfirmTimeout or the underlying AMQP error.
+type PublishError struct {
+	Exchange string
+	Key      string
+	Err      error
+}
+
+func (e *PublishError) Error() string {
+	return fmt.Sprintf("publish to %q (exchange %q): %v", e.Key, e.Exchange, e.Err)
+}
+
+func (e *PublishError) Unwrap() error {
+	return e.Err
+}
+
+// Publish sends a persistent message on a pooled channel in confirm mode and waits
+// until the broker confirms it, DefaultPublishTimeout elapses or ctx is done.
+func (c *Connection) Publish(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	if err := c.publish(ctx, exchange, key, msg); err != nil {
+		return &PublishError{Exchange: exchange, Key: key, Err: err}
+	}
+	return nil
+}
+
+func (c *Connection) publish(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	if c == nil {
+		return ErrNotConnected
+	}
+	ctx, cancel := context.WithTimeout(ctx, DefaultPublishTimeout)
+	defer cancel()
+
+	ch, err := c.publisherChannel()
+	if err != nil {
+		return err
+	}
+	msg.DeliveryMode = amqp.Persistent
+	confirmation, err := ch.PublishWithDeferredConfirmWithContext(ctx, exchange, key, false, false, msg)
+	if err != nil {
+		ch.Close()
+		return err
+	}
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		// The confirmation may still arrive on this channel, so it is not reused.
+		ch.Close()
+		if errors.Is(err, context.DeadlineExceeded) {
+			return ErrConfirmTimeout
+		}
+		return err
+	}
+	c.releasePublisherChannel(ch)
+	if !acked {
+		return ErrPublishNacked
+	}
+	return nil
+}
+
+func (c *Connection) publisherChannel() (*amqp.Channel, error) {
+	for {
+		select {
+		case ch := <-c.pool:
+			if !ch.IsClosed() {
+				return ch, nil
+			}
+		default:
+			ch, err := c.Channel()
+			if err != nil {
+				return nil, err
+			}
+			if err := ch.Confirm(false); err != nil {
+				ch.Close()
+				return nil, err
+			}
+			return ch, nil
+		}
+	}
+}
+
+func (c *Connection) releasePublisherChannel(ch *amqp.Channel) {
+	if ch.IsClosed() {
+		return
+	}
+	select {
+	case c.pool <- ch:
+	default:
+		ch.Close()
+	}
+}
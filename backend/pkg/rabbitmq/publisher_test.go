@@ -0,0 +1,67 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestPublish(t *testing.T) {
+	tests := []struct {
+		name    string
+		confirm confirmMode
+		noConn  bool
+		wantErr error
+	}{
+		{name: "acked", confirm: confirmAck},
+		{name: "not connected", noConn: true, wantErr: ErrNotConnected},
+		{name: "nacked", confirm: confirmNack, wantErr: ErrPublishNacked},
+		{name: "confirm timeout", confirm: confirmNever, wantErr: ErrConfirmTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c *Connection
+			if !tt.noConn {
+				c = dialFakeBroker(t, newFakeBroker(t, tt.confirm))
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+
+			err := c.Publish(ctx, "", "email_queue", amqp.Publishing{Body: []byte(`{}`)})
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Publish() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Publish() error = %v, want %v", err, tt.wantErr)
+			}
+			var publishErr *PublishError
+			if !errors.As(err, &publishErr) {
+				t.Fatalf("Publish() error = %T, want *PublishError", err)
+			}
+			if publishErr.Key != "email_queue" {
+				t.Errorf("PublishError.Key = %q, want email_queue", publishErr.Key)
+			}
+		})
+	}
+}
+
+func TestPublish_ReusesConfirmedChannel(t *testing.T) {
+	c := dialFakeBroker(t, newFakeBroker(t, confirmAck))
+
+	for i := 0; i < 3; i++ {
+		if err := c.Publish(context.Background(), "", "email_queue", amqp.Publishing{}); err != nil {
+			t.Fatalf("Publish() #%d error = %v", i+1, err)
+		}
+	}
+	if n := len(c.pool); n != 1 {
+		t.Errorf("pooled channels = %d, want 1", n)
+	}
+}
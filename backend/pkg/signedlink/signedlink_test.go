@@ -0,0 +1,149 @@
+package signedlink
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const (
+	testSecret   = "test-secret-of-at-least-32-characters"
+	testResource = "export_1.zip"
+)
+
+func TestVerify(t *testing.T) {
+	valid := func() url.Values {
+		return Sign(testSecret, testResource, time.Now().Add(time.Hour))
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		resource string
+		values   func() url.Values
+		wantErr  error
+	}{
+		{
+			name:     "valid link",
+			secret:   testSecret,
+			resource: testResource,
+			values:   valid,
+		},
+		{
+			name:     "tampered resource",
+			secret:   testSecret,
+			resource: "export_2.zip",
+			values:   valid,
+			wantErr:  ErrInvalidSignature,
+		},
+		{
+			name:     "tampered signature",
+			secret:   testSecret,
+			resource: testResource,
+			values: func() url.Values {
+				values := valid()
+				sig := []byte(values.Get(SignatureParam))
+				sig[0] ^= 1
+				values.Set(SignatureParam, string(sig))
+				return values
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:     "extended expiry",
+			secret:   testSecret,
+			resource: testResource,
+			values: func() url.Values {
+				values := valid()
+				values.Set(ExpiresParam, strconv.FormatInt(time.Now().Add(24*time.Hour).Unix(), 10))
+				return values
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:     "other secret",
+			secret:   "other-secret-of-at-least-32-characters",
+			resource: testResource,
+			values:   valid,
+			wantErr:  ErrInvalidSignature,
+		},
+		{
+			name:     "expired link",
+			secret:   testSecret,
+			resource: testResource,
+			values: func() url.Values {
+				return Sign(testSecret, testResource, time.Now().Add(-time.Minute))
+			},
+			wantErr: ErrLinkExpired,
+		},
+		{
+			name:     "missing signature",
+			secret:   testSecret,
+			resource: testResource,
+			values: func() url.Values {
+				values := valid()
+				values.Del(SignatureParam)
+				return values
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:     "missing expires",
+			secret:   testSecret,
+			resource: testResource,
+			values: func() url.Values {
+				values := valid()
+				values.Del(ExpiresParam)
+				return values
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:     "no parameters",
+			secret:   testSecret,
+			resource: testResource,
+			values:   func() url.Values { return url.Values{} },
+			wantErr:  ErrInvalidSignature,
+		},
+		{
+			name:     "signed malformed expires",
+			secret:   testSecret,
+			resource: testResource,
+			values: func() url.Values {
+				return url.Values{
+					ExpiresParam:   {"never"},
+					SignatureParam: {signature(testSecret, testResource, "never")},
+				}
+			},
+			wantErr: ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.resource, tt.values())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSign_DoesNotUseSecretAsKey(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+	values := Sign(testSecret, testResource, expiresAt)
+
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(testResource + ":" + values.Get(ExpiresParam)))
+	if values.Get(SignatureParam) == hex.EncodeToString(mac.Sum(nil)) {
+		t.Fatal("link is signed with the secret itself, want a derived key")
+	}
+	if got := values.Get(ExpiresParam); got != strconv.FormatInt(expiresAt.Unix(), 10) {
+		t.Errorf("expires = %s, want %d", got, expiresAt.Unix())
+	}
+}
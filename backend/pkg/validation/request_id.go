@@ -0,0 +1,11 @@
+package validation
+
+import "regexp"
+
+// requestIdRegex accepts the usual request ID formats (UUIDs, base36/hex strings, trace
+// IDs) while keeping log lines and message headers free of spaces and control characters.
+var requestIdRegex = regexp.MustCompile(`^[A-Za-z0-9._:\-]{1,64}$`)
+
+func IsRequestIdValid(requestId string) bool {
+	return requestIdRegex.MatchString(requestId)
+}
@@ -0,0 +1,3 @@
+ALTER TABLE `confirmation_tokens`
+    CHANGE `type` `type` ENUM ('register','email_change','password_change','account_deletion') NOT NULL;
+ALTER TABLE `users` ADD `deletion_scheduled_at` TIMESTAMP NULL DEFAULT NULL AFTER `confirmed_at`, ADD INDEX (`deletion_scheduled_at`);
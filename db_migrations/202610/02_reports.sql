@@ -0,0 +1,19 @@
+CREATE TABLE `reports`
+(
+    `id`          INT UNSIGNED                                       NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `user_id`     INT UNSIGNED                                       NOT NULL,
+    `type`        VARCHAR(64)                                        NOT NULL,
+    `format`      ENUM ('csv','xlsx')                                NOT NULL,
+    `params`      longtext CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL CHECK (json_valid(`params`)),
+    `status`      ENUM ('queued','running','done','failed')          NOT NULL DEFAULT 'queued',
+    `output_path` VARCHAR(255)                                       NULL     DEFAULT NULL,
+    `error`       TEXT                                               NULL     DEFAULT NULL,
+    `created_at`  TIMESTAMP                                          NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `started_at`  TIMESTAMP                                          NULL     DEFAULT NULL,
+    `finished_at` TIMESTAMP                                          NULL     DEFAULT NULL,
+    INDEX (`user_id`),
+    INDEX (`status`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
+
+ALTER TABLE `reports` ADD FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE RESTRICT ON UPDATE RESTRICT;
@@ -0,0 +1,8 @@
+CREATE TABLE `processed_messages`
+(
+    `message_id`   VARCHAR(64) NOT NULL PRIMARY KEY,
+    `task`         VARCHAR(64) NOT NULL,
+    `processed_at` TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX (`processed_at`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
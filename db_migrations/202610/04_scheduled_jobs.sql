@@ -0,0 +1,13 @@
+CREATE TABLE `scheduled_jobs`
+(
+    `id`           INT UNSIGNED                                       NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `queue`        VARCHAR(64)                                        NOT NULL,
+    `task`         VARCHAR(64)                                        NOT NULL,
+    `data`         longtext CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL CHECK (json_valid(`data`)),
+    `headers`      longtext CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL CHECK (json_valid(`headers`)),
+    `run_at`       TIMESTAMP                                          NOT NULL,
+    `published_at` TIMESTAMP                                          NULL     DEFAULT NULL,
+    `created_at`   TIMESTAMP                                          NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX (`published_at`, `run_at`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
@@ -0,0 +1,3 @@
+ALTER TABLE `confirmation_tokens`
+    ADD `resend_count` INT UNSIGNED NOT NULL DEFAULT 0 AFTER `status_changed_at`,
+    ADD `resent_at`    TIMESTAMP    NULL     DEFAULT NULL AFTER `resend_count`;
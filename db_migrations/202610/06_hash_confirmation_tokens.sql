@@ -0,0 +1,3 @@
+ALTER TABLE `confirmation_tokens`
+    CHANGE `token` `token` CHAR(64) NOT NULL;
+UPDATE `confirmation_tokens` SET `token` = SHA2(`token`, 256) WHERE CHAR_LENGTH(`token`) = 32;
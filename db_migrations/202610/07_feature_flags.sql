@@ -0,0 +1,21 @@
+CREATE TABLE `feature_flags`
+(
+    `name`            VARCHAR(64)      NOT NULL PRIMARY KEY,
+    `enabled`         TINYINT(1)       NOT NULL DEFAULT 0,
+    `rollout_percent` TINYINT UNSIGNED NOT NULL DEFAULT 100 CHECK (`rollout_percent` <= 100),
+    `updated_at`      TIMESTAMP        NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
+
+CREATE TABLE `feature_flag_overrides`
+(
+    `flag_name`  VARCHAR(64)  NOT NULL,
+    `user_id`    INT UNSIGNED NOT NULL,
+    `enabled`    TINYINT(1)   NOT NULL,
+    `created_at` TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`flag_name`, `user_id`),
+    INDEX (`user_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4;
+
+ALTER TABLE `feature_flag_overrides` ADD FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE RESTRICT ON UPDATE RESTRICT;